@@ -0,0 +1,122 @@
+// Package bump computes the next release version from versions that are already released.
+package bump
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/scylladb-actions/get-version/types"
+	"github.com/scylladb-actions/get-version/version"
+)
+
+// Branch is a release branch, like branch-6.2, versions released from it have its major and minor.
+type Branch struct {
+	Major int
+	Minor int
+}
+
+var branchVersion = regexp.MustCompile(`(\d+)\.(\d+)`)
+
+// ParseBranch takes major and minor from branch name, like branch-6.2, release/6.2 or v6.2.
+func ParseBranch(name string) (Branch, error) {
+	match := branchVersion.FindStringSubmatch(name)
+	if match == nil {
+		return Branch{}, fmt.Errorf("branch %q has no major.minor version in its name", name)
+	}
+	major, err := strconv.Atoi(match[1])
+	if err != nil {
+		return Branch{}, fmt.Errorf("failed to parse major of branch %q: %w", name, err)
+	}
+	minor, err := strconv.Atoi(match[2])
+	if err != nil {
+		return Branch{}, fmt.Errorf("failed to parse minor of branch %q: %w", name, err)
+	}
+	return Branch{Major: major, Minor: minor}, nil
+}
+
+func (b Branch) has(v version.Version) bool {
+	return v.Major() == b.Major && v.Minor() == b.Minor
+}
+
+// Result is the next release version and the version it is computed from.
+type Result struct {
+	// Previous is the newest released version, nil when a branch has no releases yet
+	Previous *version.Version
+	Next     version.Version
+}
+
+// nextRC returns extra of the next release candidate, keeping the way it is written, like .rc2 to .rc3
+func nextRC(v version.Version) string {
+	extra := v.Extra()
+	start := strings.Index(extra, "rc") + len("rc")
+	end := start
+	for end < len(extra) && extra[end] >= '0' && extra[end] <= '9' {
+		end++
+	}
+	return extra[:start] + strconv.Itoa(v.RCNumber()+1) + extra[end:]
+}
+
+// Next computes the next release version from released versions, dev and pre builds are ignored.
+// Patch, minor or major bump of 6.2.0-rc2 is 6.2.0 and rc bump is 6.2.0-rc3, rc bump of a release starts
+// release candidates of the next minor, or of the next patch on a branch. Missing patch is taken as 0,
+// other extras, like .0 of 4.18.0.0, are dropped. With branch only its versions are taken into account.
+func Next(versions version.Versions, bump types.BumpName, branch *Branch, prefix string) (Result, error) {
+	var released version.Versions
+	for _, v := range versions {
+		if !v.IsDev() && !v.IsPre() && (branch == nil || branch.has(v)) {
+			released = append(released, v)
+		}
+	}
+	if len(released) == 0 {
+		if branch == nil {
+			return Result{}, fmt.Errorf("no released versions to bump")
+		}
+		extra := ""
+		if bump == types.BumpRC {
+			extra = "-rc1"
+		}
+		next := version.New2(branch.Major, branch.Minor, 0, extra)
+		next.SetPrefix(prefix)
+		return Result{Next: next}, nil
+	}
+
+	previous, _ := released.Newest()
+	major, minor, patch := previous.Major(), previous.Minor(), previous.Patch()
+	isRC := previous.IsRC()
+	var next version.Version
+	switch bump {
+	case types.BumpRC:
+		switch {
+		case isRC:
+			next = version.New2(major, minor, patch, nextRC(previous))
+		case branch != nil:
+			next = version.New2(major, minor, patch+1, "-rc1")
+		default:
+			next = version.New2(major, minor+1, 0, "-rc1")
+		}
+	case types.BumpPatch:
+		if isRC {
+			next = version.New2(major, minor, patch, "")
+		} else {
+			next = version.New2(major, minor, patch+1, "")
+		}
+	case types.BumpMinor:
+		if isRC && patch == 0 {
+			next = version.New2(major, minor, 0, "")
+		} else {
+			next = version.New2(major, minor+1, 0, "")
+		}
+	case types.BumpMajor:
+		if isRC && minor == 0 && patch == 0 {
+			next = version.New2(major, 0, 0, "")
+		} else {
+			next = version.New2(major+1, 0, 0, "")
+		}
+	default:
+		return Result{}, fmt.Errorf("unknown bump %q", bump)
+	}
+	next.SetPrefix(prefix)
+	return Result{Previous: &previous, Next: next}, nil
+}
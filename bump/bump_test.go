@@ -0,0 +1,100 @@
+package bump
+
+import (
+	"testing"
+
+	"github.com/scylladb-actions/get-version/types"
+	"github.com/scylladb-actions/get-version/version"
+)
+
+func TestNext(t *testing.T) {
+	released := []string{"6.1.0", "6.1.5", "6.2.0-rc1", "6.2.0-rc2", "6.2.0-dev"}
+	branchReleases := []string{"6.1.5", "6.2.0-rc2", "6.2.0", "6.2.1", "6.3.0-rc1"}
+
+	tcases := []struct {
+		name     string
+		versions []string
+		bump     types.BumpName
+		branch   string
+		expected string
+		previous string
+	}{
+		{name: "next rc", versions: released, bump: types.BumpRC, expected: "6.2.0-rc3", previous: "6.2.0-rc2"},
+		{name: "release rc as patch", versions: released, bump: types.BumpPatch, expected: "6.2.0", previous: "6.2.0-rc2"},
+		{name: "release rc as minor", versions: released, bump: types.BumpMinor, expected: "6.2.0", previous: "6.2.0-rc2"},
+		{name: "major after rc", versions: released, bump: types.BumpMajor, expected: "7.0.0", previous: "6.2.0-rc2"},
+		{name: "patch", versions: []string{"6.1.5", "6.2.1"}, bump: types.BumpPatch, expected: "6.2.2", previous: "6.2.1"},
+		{name: "minor", versions: []string{"6.2.1"}, bump: types.BumpMinor, expected: "6.3.0", previous: "6.2.1"},
+		{name: "first rc of minor", versions: []string{"6.2.1"}, bump: types.BumpRC, expected: "6.3.0-rc1",
+			previous: "6.2.1"},
+		{name: "missing patch", versions: []string{"2025.1", "2024.2"}, bump: types.BumpPatch, expected: "2025.1.1",
+			previous: "2025.1"},
+		{name: "release major rc", versions: []string{"6.2.1", "7.0.0-rc1"}, bump: types.BumpMajor, expected: "7.0.0",
+			previous: "7.0.0-rc1"},
+		{name: "four parts", versions: []string{"4.17.0.1", "4.18.0.0"}, bump: types.BumpPatch, expected: "4.18.1",
+			previous: "4.18.0.0"},
+		{name: "rc number without separator", versions: []string{"6.2.0.rc9"}, bump: types.BumpRC,
+			expected: "6.2.0.rc10", previous: "6.2.0.rc9"},
+		{name: "branch patch", versions: branchReleases, bump: types.BumpPatch, branch: "branch-6.2",
+			expected: "6.2.2", previous: "6.2.1"},
+		{name: "branch rc", versions: branchReleases, bump: types.BumpRC, branch: "branch-6.2",
+			expected: "6.2.2-rc1", previous: "6.2.1"},
+		{name: "branch rc of rc", versions: branchReleases, bump: types.BumpRC, branch: "release/6.3",
+			expected: "6.3.0-rc2", previous: "6.3.0-rc1"},
+		{name: "new branch", versions: branchReleases, bump: types.BumpRC, branch: "branch-6.4", expected: "6.4.0-rc1"},
+		{name: "new branch release", versions: branchReleases, bump: types.BumpPatch, branch: "branch-6.4",
+			expected: "6.4.0"},
+	}
+
+	for _, tcase := range tcases {
+		t.Run(tcase.name, func(t *testing.T) {
+			var versions version.Versions
+			for _, v := range tcase.versions {
+				versions = append(versions, version.NewMust(v))
+			}
+			var branch *Branch
+			if tcase.branch != "" {
+				parsed, err := ParseBranch(tcase.branch)
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				branch = &parsed
+			}
+			result, err := Next(versions, tcase.bump, branch, "")
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if result.Next.String() != tcase.expected {
+				t.Errorf("expected next %q, got %q", tcase.expected, result.Next.String())
+			}
+			var previous string
+			if result.Previous != nil {
+				previous = result.Previous.String()
+			}
+			if previous != tcase.previous {
+				t.Errorf("expected previous %q, got %q", tcase.previous, previous)
+			}
+		})
+	}
+}
+
+func TestNextErrors(t *testing.T) {
+	if _, err := Next(version.Versions{version.NewMust("6.2.0-dev")}, types.BumpPatch, nil, ""); err == nil {
+		t.Errorf("expected error without released versions")
+	}
+	if _, err := ParseBranch("master"); err == nil {
+		t.Errorf("expected error for branch without version")
+	}
+}
+
+func TestNextPrefix(t *testing.T) {
+	previous := version.NewMust("6.2.1")
+	previous.SetPrefix("scylla-")
+	result, err := Next(version.Versions{previous}, types.BumpPatch, nil, "scylla-")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Next.String() != "scylla-6.2.2" {
+		t.Errorf("expected %q, got %q", "scylla-6.2.2", result.Next.String())
+	}
+}
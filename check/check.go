@@ -0,0 +1,157 @@
+// Package check compares the version currently in use against versions found in a source.
+package check
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/scylladb-actions/get-version/pin"
+	"github.com/scylladb-actions/get-version/types"
+	"github.com/scylladb-actions/get-version/version"
+)
+
+// Kind tells which part of the version an update changes.
+type Kind string
+
+const (
+	KindNone  Kind = "none"
+	KindPatch Kind = "patch"
+	KindMinor Kind = "minor"
+	KindMajor Kind = "major"
+)
+
+// Classify returns kind of update from one version to a higher one.
+// Updates that change only extra, like 6.1.0-rc1 to 6.1.0 or 6.1.0-rc2, are patch updates.
+func Classify(from, to version.Version) Kind {
+	switch {
+	case to.CmpRC(from) <= 0:
+		return KindNone
+	case to.Major() != from.Major():
+		return KindMajor
+	case to.Minor() != from.Minor():
+		return KindMinor
+	default:
+		return KindPatch
+	}
+}
+
+// Result is an outcome of comparing the current version against versions from a source.
+type Result struct {
+	Current version.Version
+	// Pin is where the current version was read from, nil when it was given as is
+	Pin *pin.Pin
+	// Latest is the highest version from the source, nil when no versions match filters
+	Latest *version.Version
+	Kind   Kind
+}
+
+func (r Result) UpdateAvailable() bool {
+	return r.Kind != KindNone
+}
+
+// NewValue returns the version to update to as it should be pinned, with prefix only if the current version has it.
+func (r Result) NewValue() string {
+	if r.Latest == nil {
+		return ""
+	}
+	return r.Current.Prefix() + r.Latest.NoPrefixString()
+}
+
+// Compare compares current version against candidates, which are versions from the source that match filters.
+func Compare(current version.Version, candidates version.Versions) Result {
+	result := Result{Current: current, Kind: KindNone}
+	latest, ok := candidates.Newest()
+	if !ok {
+		return result
+	}
+	result.Latest = &latest
+	result.Kind = Classify(current, latest)
+	return result
+}
+
+// Current returns version currently in use from --current or reads it from --current-file.
+// The pin is nil when the version is given by --current.
+func Current(p types.Params) (version.Version, *pin.Pin, error) {
+	if p.Current != "" {
+		current, err := parseCurrent(p.Current, p.Prefix)
+		return current, nil, err
+	}
+
+	locator, err := currentLocator(p)
+	if err != nil {
+		return version.Version{}, nil, err
+	}
+	found, err := pin.Find(p.CurrentFile, locator)
+	if err != nil {
+		return version.Version{}, nil, err
+	}
+	current, err := parseCurrent(found.Value, p.Prefix)
+	if err != nil {
+		return version.Version{}, nil, fmt.Errorf("%s: %w", found, err)
+	}
+	return current, &found, nil
+}
+
+// Update writes the new version to the file the current version was read from. With dryRun the file is left as is
+// and unified diff of the update is written to diffOutput instead. Nothing is done when there is no update.
+func Update(result Result, dryRun bool, diffOutput io.Writer) error {
+	if !result.UpdateAvailable() {
+		return nil
+	}
+	if result.Pin == nil {
+		return fmt.Errorf("current version was not read from a file, there is nothing to update")
+	}
+	before, after, err := pin.Update(*result.Pin, result.NewValue(), dryRun)
+	if err != nil {
+		return err
+	}
+	if dryRun {
+		_, err = io.WriteString(diffOutput, pin.UnifiedDiff(result.Pin.Path, before, after))
+	}
+	return err
+}
+
+// currentLocator returns locator for --current-file, --current-name defaults to what is looked up in the source
+func currentLocator(p types.Params) (pin.Locator, error) {
+	if p.CurrentPath != "" && p.CurrentPattern != "" {
+		inner, err := pin.NewRegexp(p.CurrentPattern)
+		if err != nil {
+			return nil, err
+		}
+		return pin.Within{Outer: pin.Path{Path: p.CurrentPath}, Inner: inner}, nil
+	}
+	if p.CurrentPath != "" {
+		return pin.Path{Path: p.CurrentPath}, nil
+	}
+	if p.CurrentPattern != "" {
+		return pin.NewRegexp(p.CurrentPattern)
+	}
+	name := p.CurrentName
+	if name == "" {
+		switch p.SourceName {
+		case types.DockerHubImageTag:
+			name = p.Repo
+		case types.MavenArtifact:
+			name = p.MavenArtifactID
+		case types.GitHubRelease, types.GitHubTag:
+			if strings.HasSuffix(p.CurrentFile, "go.mod") {
+				name = "github.com/" + p.Repo
+			}
+		}
+	}
+	return pin.ForFile(p.CurrentFile, name)
+}
+
+// parseCurrent parses the current version, prefix is optional, so that both v1.2.3 and 1.2.3 match --prefix=v
+func parseCurrent(value, prefix string) (version.Version, error) {
+	trimmed, hasPrefix := strings.CutPrefix(value, prefix)
+	current, err := version.New(trimmed)
+	if err != nil {
+		return version.Version{}, fmt.Errorf("failed to parse current version %q: %w", value, err)
+	}
+	if hasPrefix {
+		current.SetPrefix(prefix)
+	}
+	return current, nil
+}
@@ -0,0 +1,188 @@
+package check
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/scylladb-actions/get-version/types"
+	"github.com/scylladb-actions/get-version/version"
+)
+
+func TestCompare(t *testing.T) {
+	tcases := []struct {
+		name       string
+		current    string
+		candidates []string
+		expected   Kind
+		latest     string
+	}{
+		{name: "patch", current: "6.1.3", candidates: []string{"6.1.3", "6.1.5", "6.1.4"}, expected: KindPatch},
+		{name: "minor", current: "6.1.3", candidates: []string{"6.1.5", "6.2.0"}, expected: KindMinor},
+		{name: "major", current: "6.1.3", candidates: []string{"6.2.0", "2025.1.0"}, expected: KindMajor},
+		{name: "rc to release", current: "6.2.0-rc1", candidates: []string{"6.2.0"}, expected: KindPatch},
+		{name: "up to date", current: "6.2.0", candidates: []string{"6.1.5", "6.2.0"}, expected: KindNone},
+		{name: "newer than source", current: "6.3.0", candidates: []string{"6.2.0"}, expected: KindNone},
+		{name: "no candidates", current: "6.1.3", expected: KindNone},
+		{
+			name:       "newest rc",
+			current:    "6.1.5",
+			candidates: []string{"6.2.0-rc2", "6.1.5", "6.2.0-rc1"},
+			expected:   KindMinor,
+			latest:     "6.2.0-rc2",
+		},
+		{
+			name:       "rc to next rc",
+			current:    "6.2.0-rc1",
+			candidates: []string{"6.2.0-rc2", "6.2.0-rc1"},
+			expected:   KindPatch,
+			latest:     "6.2.0-rc2",
+		},
+	}
+
+	for _, tcase := range tcases {
+		t.Run(tcase.name, func(t *testing.T) {
+			var candidates version.Versions
+			for _, candidate := range tcase.candidates {
+				candidates = append(candidates, version.NewMust(candidate))
+			}
+			result := Compare(version.NewMust(tcase.current), candidates)
+			if result.Kind != tcase.expected {
+				t.Errorf("expected %s, got %s", tcase.expected, result.Kind)
+			}
+			if result.UpdateAvailable() != (tcase.expected != KindNone) {
+				t.Errorf("unexpected update available %v", result.UpdateAvailable())
+			}
+			if (result.Latest == nil) != (len(tcase.candidates) == 0) {
+				t.Errorf("unexpected latest %v", result.Latest)
+			}
+			if tcase.latest != "" && result.Latest.String() != tcase.latest {
+				t.Errorf("expected latest %s, got %s", tcase.latest, result.Latest)
+			}
+		})
+	}
+}
+
+func TestCurrent(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"Dockerfile":  "FROM scylladb/scylla:6.1.3\n",
+		"go.mod":      "module app\n\nrequire github.com/scylladb/gocql v1.14.4\n",
+		"versions.sh": "SCYLLA=scylla-6.2.0\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatalf("failed to write file: %v", err)
+		}
+	}
+
+	tcases := []struct {
+		name     string
+		params   types.Params
+		expected string
+		pinned   bool
+	}{
+		{
+			name:     "value",
+			params:   types.Params{Current: "6.1.3"},
+			expected: "6.1.3",
+		},
+		{
+			name:     "value without prefix",
+			params:   types.Params{Current: "6.1.3", Prefix: "scylla-"},
+			expected: "6.1.3",
+		},
+		{
+			name: "dockerfile",
+			params: types.Params{
+				SourceName:  types.DockerHubImageTag,
+				Repo:        "scylladb/scylla",
+				CurrentFile: filepath.Join(dir, "Dockerfile"),
+			},
+			expected: "6.1.3",
+			pinned:   true,
+		},
+		{
+			name: "go.mod module from repo",
+			params: types.Params{
+				SourceName:  types.GitHubTag,
+				Repo:        "scylladb/gocql",
+				Prefix:      "v",
+				CurrentFile: filepath.Join(dir, "go.mod"),
+			},
+			expected: "v1.14.4",
+			pinned:   true,
+		},
+		{
+			name: "pattern",
+			params: types.Params{
+				Prefix:         "scylla-",
+				CurrentFile:    filepath.Join(dir, "versions.sh"),
+				CurrentPattern: `SCYLLA=(\S+)`,
+			},
+			expected: "scylla-6.2.0",
+			pinned:   true,
+		},
+	}
+
+	for _, tcase := range tcases {
+		t.Run(tcase.name, func(t *testing.T) {
+			current, currentPin, err := Current(tcase.params)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if current.String() != tcase.expected {
+				t.Errorf("expected %q, got %q", tcase.expected, current.String())
+			}
+			if (currentPin != nil) != tcase.pinned {
+				t.Errorf("unexpected pin %v", currentPin)
+			}
+		})
+	}
+
+	if _, _, err := Current(types.Params{Current: "latest"}); err == nil {
+		t.Errorf("expected error for current version that is not a version")
+	}
+}
+
+func TestUpdate(t *testing.T) {
+	dir := t.TempDir()
+	t.Chdir(dir)
+	path := filepath.Join(dir, "go.mod")
+	if err := os.WriteFile(path, []byte("module app\n\nrequire github.com/scylladb/gocql v1.14.4\n"), 0o644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+	params := types.Params{SourceName: types.GitHubTag, Repo: "scylladb/gocql", Prefix: "v", CurrentFile: path}
+	current, currentPin, err := Current(params)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	latest := version.NewMust("1.15.0")
+	latest.SetPrefix("v")
+	result := Compare(current, version.Versions{latest})
+	result.Pin = currentPin
+
+	var diff bytes.Buffer
+	if err = Update(result, true, &diff); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := "--- a/go.mod\n+++ b/go.mod\n@@ -1,3 +1,3 @@\n" +
+		" module app\n \n-require github.com/scylladb/gocql v1.14.4\n+require github.com/scylladb/gocql v1.15.0\n"
+	if diff.String() != expected {
+		t.Errorf("expected diff:\n%s\ngot:\n%s", expected, diff.String())
+	}
+
+	if err = Update(result, false, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if content, _ := os.ReadFile(path); !strings.Contains(string(content), "gocql v1.15.0\n") {
+		t.Errorf("file is not updated: %q", content)
+	}
+
+	result.Pin = nil
+	if err = Update(result, false, nil); err == nil {
+		t.Errorf("expected error for current version given as is")
+	}
+}
@@ -0,0 +1,163 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/scylladb-actions/get-version/filters"
+	"github.com/scylladb-actions/get-version/sources"
+	"github.com/scylladb-actions/get-version/types"
+)
+
+// runCommand runs commands that do not list versions, it returns false for the ones that do
+func runCommand(p types.Params) (exitCode int, done bool) {
+	switch p.Command {
+	case types.CommandValidateFilter:
+		return validateFilter(p), true
+	case types.CommandExplain:
+		return explain(p), true
+	case types.CommandSources:
+		printSources()
+		return 0, true
+	case types.CommandCompletion:
+		fmt.Fprint(os.Stdout, completionScript(p.Args[0]))
+		return 0, true
+	case types.CommandServe:
+		return serve(p), true
+	default:
+		return 0, false
+	}
+}
+
+func validateFilter(p types.Params) int {
+	if _, err := filters.ParseFilterString(p.FiltersDefinition); err != nil {
+		fmt.Fprintln(os.Stderr, err.Error())
+		return exitParseError
+	}
+	fmt.Fprintf(os.Stdout, "%q is valid\n", p.FiltersDefinition)
+	return 0
+}
+
+// explain describes the filter, with --source it also gets versions and tells what every part of the filter selects
+func explain(p types.Params) int {
+	filter, err := filters.ParseFilterString(p.FiltersDefinition)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err.Error())
+		return exitParseError
+	}
+	if p.SourceName == "" {
+		fmt.Fprintln(os.Stdout, strings.Join(filters.Explain(filter), "\n"))
+		return 0
+	}
+
+	run, err := newQueryRun(types.Query{Params: p})
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err.Error())
+		return exitParseError
+	}
+	run.fetch()
+	if run.err != nil {
+		fmt.Fprintln(os.Stderr, run.err.Error())
+		return exitSourceError
+	}
+	fmt.Fprintf(os.Stdout, "%s returned %d versions, %d were ignored\n", p.SourceName, len(run.all), len(run.ignored))
+	fmt.Fprintln(os.Stdout, strings.Join(filters.ExplainVersions(filter, run.all), "\n"))
+	return 0
+}
+
+// printSources prints sources with flags that apply only to them, other flags apply to every source
+func printSources() {
+	names := sources.AllSources.Names()
+	slices.Sort(names)
+	width := 0
+	for _, name := range names {
+		width = max(width, len(name))
+	}
+	for _, name := range names {
+		var flags []string
+		for _, f := range types.SourceFlags(types.SourceName(name)) {
+			if f.Required {
+				flags = append(flags, "--"+f.Name+" (required)")
+			} else {
+				flags = append(flags, "--"+f.Name)
+			}
+		}
+		fmt.Fprintf(os.Stdout, "%-*s  %s\n", width, name, strings.Join(flags, ", "))
+	}
+}
+
+// completionScript returns a script that completes commands, flags and source names in the shell
+func completionScript(shell string) string {
+	var commands, flagNames []string
+	for _, command := range types.Commands {
+		commands = append(commands, string(command.Name))
+	}
+	var fishFlags strings.Builder
+	flag.CommandLine.VisitAll(func(f *flag.Flag) {
+		flagNames = append(flagNames, "--"+f.Name)
+		usage, _, _ := strings.Cut(f.Usage, "\n")
+		fmt.Fprintf(&fishFlags, "complete -c get-version -l %s -d '%s'\n", f.Name, strings.ReplaceAll(usage, "'", `\'`))
+	})
+	sourceNames := sources.AllSources.Names()
+	slices.Sort(sourceNames)
+
+	words := strings.NewReplacer(
+		"COMMANDS", strings.Join(commands, " "),
+		"FLAGS", strings.Join(flagNames, " "),
+		"SOURCES", strings.Join(sourceNames, " "),
+		"SHELLS", strings.Join(types.CompletionShells, " "),
+	)
+	switch shell {
+	case "zsh":
+		return words.Replace(zshCompletion)
+	case "fish":
+		return words.Replace(fishCompletion) + fishFlags.String()
+	default:
+		return words.Replace(bashCompletion)
+	}
+}
+
+const bashCompletion = `_get_version() {
+	local cur="${COMP_WORDS[COMP_CWORD]}" prev="${COMP_WORDS[COMP_CWORD-1]}"
+	case "$prev" in
+	--source)
+		COMPREPLY=($(compgen -W "SOURCES" -- "$cur"))
+		return
+		;;
+	completion)
+		COMPREPLY=($(compgen -W "SHELLS" -- "$cur"))
+		return
+		;;
+	esac
+	if [[ $COMP_CWORD -eq 1 && "$cur" != -* ]]; then
+		COMPREPLY=($(compgen -W "COMMANDS" -- "$cur"))
+		return
+	fi
+	COMPREPLY=($(compgen -W "FLAGS" -- "$cur"))
+}
+complete -F _get_version get-version
+`
+
+const zshCompletion = `#compdef get-version
+_get_version() {
+	if (( CURRENT == 2 )) && [[ $words[CURRENT] != -* ]]; then
+		compadd -- COMMANDS
+	elif [[ $words[CURRENT-1] == --source ]]; then
+		compadd -- SOURCES
+	elif [[ $words[CURRENT-1] == completion ]]; then
+		compadd -- SHELLS
+	else
+		compadd -- FLAGS
+	fi
+}
+compdef _get_version get-version
+`
+
+const fishCompletion = `complete -c get-version -f
+complete -c get-version -n __fish_use_subcommand -a 'COMMANDS'
+complete -c get-version -n '__fish_seen_subcommand_from completion' -a 'SHELLS'
+complete -c get-version -l source -x -a 'SOURCES'
+`
@@ -0,0 +1,94 @@
+package filters
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/scylladb-actions/get-version/version"
+)
+
+// explainedVersions is how many selected versions a line of explanation lists
+const explainedVersions = 10
+
+// Explain describes how the filter selects versions, a line per filter, nested filters are indented.
+func Explain(f Filter) []string {
+	return explain(f, nil, false, "")
+}
+
+// ExplainVersions is Explain that also tells which versions every filter selects, filters of and
+// select from the result of the previous filter.
+func ExplainVersions(f Filter, versions version.Versions) []string {
+	return explain(f, versions, true, "")
+}
+
+func explain(f Filter, versions version.Versions, applied bool, indent string) []string {
+	var line string
+	var nested []string
+	switch f := f.(type) {
+	case EmptyFilter:
+		line = "no filter: all versions"
+	case GlobalPosition:
+		line = fmt.Sprintf("%s: %s", f, describePosition(f.keyword, f.offset, "version"))
+	case Pattern:
+		line = fmt.Sprintf("%s: major %s, minor %s, patch %s",
+			f, f.major.describe(""), f.minor.describe(" of each major"), f.patch.describe(" of each major.minor"))
+	case And:
+		line = "all of, each filter selects from the result of the previous one:"
+		input := versions
+		for _, filter := range f {
+			nested = append(nested, explain(filter, input, applied, indent+"  ")...)
+			input = filter.Apply(slices.Clone(input))
+		}
+	case Or:
+		line = "any of, results are merged:"
+		for _, filter := range f {
+			nested = append(nested, explain(filter, versions, applied, indent+"  ")...)
+		}
+	default:
+		line = fmt.Sprintf("%T", f)
+	}
+	if applied {
+		line += " -> " + describeSelected(f.Apply(slices.Clone(versions)))
+	}
+	return append([]string{indent + line}, nested...)
+}
+
+// describe tells which values of a version part the pattern selects, scope tells where positions are counted
+func (p StringPattern) describe(scope string) string {
+	switch {
+	case p.isAny():
+		return "any"
+	case p.isFirst():
+		return describePosition("FIRST", getIdxMust(p.asString(), "FIRST", '+'), "value") + scope
+	case p.isLast():
+		return describePosition("LAST", getIdxMust(p.asString(), "LAST", '-'), "value") + scope
+	case p.isRegexp():
+		return "matching " + p.asString()
+	default:
+		return p.asString()
+	}
+}
+
+// describePosition describes FIRST+N and LAST-N positions among versions or among values of a version part
+func describePosition(keyword string, offset int, of string) string {
+	extreme, direction := "highest", "below"
+	if keyword == "FIRST" {
+		extreme, direction = "lowest", "above"
+	}
+	if offset == 0 {
+		return fmt.Sprintf("the %s %s", extreme, of)
+	}
+	return fmt.Sprintf("%d %s the %s %s", offset, direction, extreme, of)
+}
+
+func describeSelected(versions version.Versions) string {
+	if len(versions) == 0 {
+		return "nothing"
+	}
+	names := versions.Order(false).AsStringSlice(true)
+	if len(names) > explainedVersions {
+		names = append(names[:explainedVersions], fmt.Sprintf("and %d more", len(versions)-explainedVersions))
+	}
+	return fmt.Sprintf("%d: %s", len(versions), strings.Join(names, ", "))
+}
@@ -0,0 +1,63 @@
+package filters
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/scylladb-actions/get-version/version"
+)
+
+func TestExplain(t *testing.T) {
+	tcases := []struct {
+		filter   string
+		expected []string
+	}{
+		{filter: "", expected: []string{"no filter: all versions"}},
+		{filter: "LAST-1", expected: []string{"LAST-1: 1 below the highest version"}},
+		{filter: "LAST.*.LAST", expected: []string{
+			"LAST.*.LAST: major the highest value, minor any, patch the highest value of each major.minor",
+		}},
+		{filter: "6.FIRST+1.([0-9]+)", expected: []string{
+			"6.FIRST+1.([0-9]+): major 6, minor 1 above the lowest value of each major, patch matching ([0-9]+)",
+		}},
+		{filter: "LAST or FIRST", expected: []string{
+			"any of, results are merged:",
+			"  LAST: the highest version",
+			"  FIRST: the lowest version",
+		}},
+	}
+
+	for _, tcase := range tcases {
+		t.Run(tcase.filter, func(t *testing.T) {
+			filter, err := ParseFilterString(tcase.filter)
+			if err != nil {
+				t.Fatalf("failed to parse filter: %v", err)
+			}
+			if got := Explain(filter); !slices.Equal(got, tcase.expected) {
+				t.Errorf("expected %q, got %q", tcase.expected, got)
+			}
+		})
+	}
+}
+
+func TestExplainVersions(t *testing.T) {
+	var versions version.Versions
+	for _, v := range []string{"6.2.1", "6.1.5", "6.2.0", "5.4.9"} {
+		versions = append(versions, version.NewMust(v))
+	}
+	filter, err := ParseFilterString("LAST.*.* and LAST-1")
+	if err != nil {
+		t.Fatalf("failed to parse filter: %v", err)
+	}
+	expected := []string{
+		"all of, each filter selects from the result of the previous one: -> 1: 6.2.0",
+		"  LAST.*.*: major the highest value, minor any, patch any -> 3: 6.1.5, 6.2.0, 6.2.1",
+		"  LAST-1: 1 below the highest version -> 1: 6.2.0",
+	}
+	if got := ExplainVersions(filter, versions); !slices.Equal(got, expected) {
+		t.Errorf("expected %q, got %q", expected, got)
+	}
+	if !slices.Equal(versions.AsStringSlice(true), []string{"6.2.1", "6.1.5", "6.2.0", "5.4.9"}) {
+		t.Errorf("explain changed order of versions %v", versions)
+	}
+}
@@ -11,6 +11,23 @@ type Filter interface {
 	Apply(versions version.Versions) version.Versions
 }
 
+// NewestLimited is implemented by filters that select versions only among the highest ones, like LAST.
+type NewestLimited interface {
+	// NewestNeeded returns how many highest versions the filter needs to select its result,
+	// 0 when it needs all versions.
+	NewestNeeded() int
+}
+
+// NewestNeeded returns how many highest versions filter needs, 0 when it needs all versions.
+// Sources that list versions from newest to oldest can stop listing after that many versions
+// if the newest versions are expected to be the highest.
+func NewestNeeded(f Filter) int {
+	if limited, ok := f.(NewestLimited); ok {
+		return limited.NewestNeeded()
+	}
+	return 0
+}
+
 type EmptyFilter struct{}
 
 func (f EmptyFilter) Apply(versions version.Versions) version.Versions {
@@ -0,0 +1,34 @@
+package filters
+
+import "testing"
+
+func TestNewestNeeded(t *testing.T) {
+	tcases := []struct {
+		filter   string
+		expected int
+	}{
+		{filter: "", expected: 0},
+		{filter: "LAST", expected: 1},
+		{filter: "LAST-2", expected: 3},
+		{filter: "FIRST", expected: 0},
+		{filter: "LAST.LAST.LAST", expected: 1},
+		{filter: "LAST.*.*", expected: 0},
+		{filter: "LAST.LAST-1.LAST", expected: 0},
+		{filter: "LAST-1 and 6.*.*", expected: 2},
+		{filter: "6.*.* and LAST", expected: 0},
+		{filter: "LAST or LAST-3", expected: 4},
+		{filter: "LAST or FIRST", expected: 0},
+	}
+
+	for _, tcase := range tcases {
+		t.Run(tcase.filter, func(t *testing.T) {
+			filter, err := ParseFilterString(tcase.filter)
+			if err != nil {
+				t.Fatalf("failed to parse filter: %v", err)
+			}
+			if got := NewestNeeded(filter); got != tcase.expected {
+				t.Fatalf("expected %d, got %d", tcase.expected, got)
+			}
+		})
+	}
+}
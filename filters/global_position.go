@@ -67,6 +67,14 @@ func (f GlobalPosition) Apply(versions version.Versions) version.Versions {
 	return version.Versions{sorted[index]}
 }
 
+// NewestNeeded returns N+1 for LAST-N, FIRST needs all versions
+func (f GlobalPosition) NewestNeeded() int {
+	if f.keyword == "LAST" {
+		return f.offset + 1
+	}
+	return 0
+}
+
 // String returns a string representation of the filter
 func (f GlobalPosition) String() string {
 	if f.offset == 0 {
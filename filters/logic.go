@@ -16,6 +16,14 @@ func (f And) Apply(versions version.Versions) version.Versions {
 	return out
 }
 
+// NewestNeeded returns need of the first filter, the rest of filters only narrow its result down
+func (f And) NewestNeeded() int {
+	if len(f) == 0 {
+		return 0
+	}
+	return NewestNeeded(f[0])
+}
+
 type Or []Filter
 
 func NewOr(filters ...Filter) Or {
@@ -36,3 +44,16 @@ func (f Or) Apply(versions version.Versions) version.Versions {
 	}
 	return out
 }
+
+// NewestNeeded returns the largest need of the filters, 0 if any of them needs all versions
+func (f Or) NewestNeeded() int {
+	needed := 0
+	for _, filter := range f {
+		n := NewestNeeded(filter)
+		if n == 0 {
+			return 0
+		}
+		needed = max(needed, n)
+	}
+	return needed
+}
@@ -158,6 +158,14 @@ func (f Pattern) Apply(versions version.Versions) version.Versions {
 	})
 }
 
+// NewestNeeded returns 1 for LAST.LAST.LAST, which selects the highest version, 0 for other patterns
+func (f Pattern) NewestNeeded() int {
+	if f.major == "LAST" && f.minor == "LAST" && f.patch == "LAST" {
+		return 1
+	}
+	return 0
+}
+
 func (f Pattern) Validate() error {
 	return errors.Join(
 		wrapErr(f.major.Validate(), "failed to validate major pattern"),
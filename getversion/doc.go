@@ -0,0 +1,19 @@
+// Package getversion gets versions from a source and selects them by a filter, like the get-version CLI,
+// for Go programs that embed it instead of running the binary.
+//
+//	result, err := getversion.Query{
+//		Source: getversion.SourceGitHubRelease,
+//		Repo:   "scylladb/scylla",
+//		Prefix: "scylla-",
+//		Filter: "LAST.*.LAST",
+//	}.Run(ctx)
+//
+// # Compatibility
+//
+// This package is the only public API of the module and follows semantic versioning of its tags:
+// within a major version exported identifiers are not removed or changed incompatibly, fields are added to
+// option structs only with zero values that keep the previous behavior, and errors keep matching the same
+// sentinel errors with errors.Is. Other packages of the module are internal to the CLI, they can change in any
+// release even though Version, Versions and IgnoredVersion are aliases of their types. Methods of those types
+// that are documented in this package are covered by the policy as well.
+package getversion
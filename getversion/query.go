@@ -0,0 +1,248 @@
+package getversion
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/scylladb-actions/get-version/filters"
+	"github.com/scylladb-actions/get-version/sources"
+	"github.com/scylladb-actions/get-version/types"
+	"github.com/scylladb-actions/get-version/version"
+)
+
+// Version is a parsed version, String gives it with its prefix and NoPrefixString without it,
+// Major, Minor and PatchRaw give its parts and Cmp orders versions.
+type Version = version.Version
+
+// Versions is a list of versions.
+type Versions = version.Versions
+
+// IgnoredVersion is a version the source returned that can't be parsed, Reason tells why.
+type IgnoredVersion = types.IgnoredVersion
+
+// SourceName names a source of versions.
+type SourceName = types.SourceName
+
+const (
+	SourceGitHubRelease     = types.GitHubRelease
+	SourceGitHubTag         = types.GitHubTag
+	SourceDockerHubImageTag = types.DockerHubImageTag
+	SourceMavenArtifact     = types.MavenArtifact
+	SourceFile              = types.File
+	SourceStdin             = types.Stdin
+)
+
+// GitHubAPI is the GitHub API used for GitHub sources.
+type GitHubAPI = types.GitHubAPIName
+
+const (
+	// GitHubAPIAuto uses GraphQL API when GitHub credentials are provided and REST API otherwise
+	GitHubAPIAuto    = types.GitHubAPIAuto
+	GitHubAPIREST    = types.GitHubAPIREST
+	GitHubAPIGraphQL = types.GitHubAPIGraphQL
+)
+
+var (
+	// ErrInvalidQuery is returned by Run when options of the query are invalid
+	ErrInvalidQuery = errors.New("invalid query")
+	// ErrInvalidFilter is returned by Run when the filter can't be parsed
+	ErrInvalidFilter = errors.New("invalid filter")
+	// ErrSource is returned by Run when versions can't be got from the source, errors of ctx are wrapped as well
+	ErrSource = errors.New("failed to get versions")
+)
+
+// Query gets versions from a source. Zero values of options are the defaults of the matching CLI flags,
+// options that are not set are taken from env variables like the CLI does: the GitHub token from GH_TOKEN or
+// GITHUB_TOKEN, the GitHub API URL from GITHUB_API_URL and GitHub App credentials from GITHUB_APP_* variables.
+type Query struct {
+	Source SourceName
+	// Repo is an image like library/ubuntu for Docker Hub and owner/name for GitHub
+	Repo string
+	// Filter selects versions with the filter syntax of --filters, all versions are selected when it is empty
+	Filter string
+	// Prefix is stripped from versions before they are parsed, versions without it are ignored
+	Prefix string
+	// EarlyStop stops listing versions once the filter is satisfied, see --early-stop
+	EarlyStop bool
+	// File is read by SourceFile, a version per line or a JSON or YAML list, - reads standard input
+	File string
+
+	Maven     MavenOptions
+	DockerHub DockerHubOptions
+	GitHub    GitHubOptions
+	HTTP      HTTPOptions
+	Cache     CacheOptions
+
+	// Log receives warnings, like a disabled TLS verification or a cache that can't be written,
+	// and GitHub Actions commands that mask tokens, nothing is written when it is nil
+	Log io.Writer
+}
+
+// MavenOptions are options of the maven-artifact source.
+type MavenOptions struct {
+	Group      string
+	ArtifactID string
+	// URL is the search API base URL, Maven Central by default
+	URL string
+}
+
+// DockerHubOptions are options of the dockerhub-imagetag source.
+type DockerHubOptions struct {
+	// URL is the API base URL, Docker Hub by default
+	URL string
+}
+
+// GitHubOptions are options of the github-release and github-tag sources.
+type GitHubOptions struct {
+	Token string
+	API   GitHubAPI
+	// APIURL is the API base URL, https://<host>/api/v3 for GitHub Enterprise Server
+	APIURL string
+
+	AppID string
+	// AppPrivateKey is PEM of the GitHub App private key, AppPrivateKeyFile is a file with it
+	AppPrivateKey     string
+	AppPrivateKeyFile string
+	// AppInstallationID is looked up by Repo when it is 0
+	AppInstallationID int64
+}
+
+// HTTPOptions are options of the http client that sources use.
+type HTTPOptions struct {
+	// RequestTimeout limits a single request attempt, negative value removes the limit
+	RequestTimeout time.Duration
+	// RetryMax is the maximum number of retries of a request, negative value disables retries
+	RetryMax          int
+	RetryInitialDelay time.Duration
+	RetryMaxDelay     time.Duration
+	// Concurrency is the maximum number of pages of paginated API responses fetched at once
+	Concurrency int
+
+	Insecure bool
+	// CABundle is a PEM file or a directory of PEM files with CA certificates trusted in addition to system ones
+	CABundle   string
+	ClientCert string
+	ClientKey  string
+	// NetworkConfig is a YAML file with proxy and per-host overrides, see --network-config
+	NetworkConfig string
+
+	// Record and Replay are directories to record responses to and to replay them from, for tests
+	Record string
+	Replay string
+}
+
+// CacheOptions are options of the on-disk response cache, it is off unless one of them is set.
+type CacheOptions struct {
+	// Enabled turns the cache on in $XDG_CACHE_HOME/get-version unless Dir is set
+	Enabled bool
+	Dir     string
+	// TTL is how long cached responses are used without revalidation
+	TTL     time.Duration
+	Offline bool
+}
+
+// Result is versions the source returned and versions the filter selected of them.
+type Result struct {
+	// Versions are selected by the filter, in the order the filter gives them
+	Versions Versions
+	// All are versions the source returned
+	All     Versions
+	Ignored []IgnoredVersion
+}
+
+// Latest returns the newest selected version, release candidates of the same version are compared by their number,
+// false when the filter selected none.
+func (r Result) Latest() (Version, bool) {
+	return r.Versions.Newest()
+}
+
+// Run gets versions from the source and selects them by the filter, the error matches one of ErrInvalidQuery,
+// ErrInvalidFilter and ErrSource.
+func (q Query) Run(ctx context.Context) (Result, error) {
+	filter, err := filters.ParseFilterString(q.Filter)
+	if err != nil {
+		return Result{}, fmt.Errorf("%w: %w", ErrInvalidFilter, err)
+	}
+	p, err := q.params()
+	if err != nil {
+		return Result{}, fmt.Errorf("%w: %w", ErrInvalidQuery, err)
+	}
+	if p.EarlyStop {
+		p.NewestVersions = filters.NewestNeeded(filter)
+	}
+	source, err := sources.AllSources.GetSource(p)
+	if err != nil {
+		return Result{}, fmt.Errorf("%w: %w", ErrInvalidQuery, err)
+	}
+
+	all, ignored, err := source.GetAllVersions(ctx)
+	if err != nil {
+		return Result{}, fmt.Errorf("%w: %w", ErrSource, err)
+	}
+	return Result{Versions: filter.Apply(all), All: all, Ignored: ignored}, nil
+}
+
+// params converts the query to params of the CLI, options that are not set keep defaults of the flags
+func (q Query) params() (types.Params, error) {
+	p := types.DefaultParams()
+	p.SourceName = q.Source
+	p.Repo = q.Repo
+	p.FiltersDefinition = q.Filter
+	p.Prefix = q.Prefix
+	p.EarlyStop = q.EarlyStop
+	p.File = q.File
+
+	p.MavenGroup = q.Maven.Group
+	p.MavenArtifactID = q.Maven.ArtifactID
+	setIfNotZero(&p.MavenURL, q.Maven.URL)
+	setIfNotZero(&p.DockerHubURL, q.DockerHub.URL)
+
+	p.GitHubToken = q.GitHub.Token
+	setIfNotZero(&p.GitHubAPI, q.GitHub.API)
+	p.GitHubAPIURL = q.GitHub.APIURL
+	p.GitHubAppID = q.GitHub.AppID
+	p.GitHubAppPrivateKey = q.GitHub.AppPrivateKey
+	p.GitHubAppPrivateKeyFile = q.GitHub.AppPrivateKeyFile
+	p.GitHubAppInstallationID = q.GitHub.AppInstallationID
+
+	setLimit(&p.RequestTimeout, q.HTTP.RequestTimeout)
+	setLimit(&p.RetryMax, q.HTTP.RetryMax)
+	setIfNotZero(&p.RetryInitialDelay, int(q.HTTP.RetryInitialDelay.Milliseconds()))
+	setIfNotZero(&p.RetryMaxDelay, int(q.HTTP.RetryMaxDelay.Milliseconds()))
+	setIfNotZero(&p.Concurrency, q.HTTP.Concurrency)
+	p.Insecure = q.HTTP.Insecure
+	p.CABundle = q.HTTP.CABundle
+	p.ClientCert = q.HTTP.ClientCert
+	p.ClientKey = q.HTTP.ClientKey
+	p.NetworkConfig = q.HTTP.NetworkConfig
+	p.HTTPRecord = q.HTTP.Record
+	p.HTTPReplay = q.HTTP.Replay
+
+	p.Cache = q.Cache.Enabled
+	p.CacheDir = q.Cache.Dir
+	setIfNotZero(&p.CacheTTL, q.Cache.TTL)
+	p.Offline = q.Cache.Offline
+	p.Log = q.Log
+
+	return p, p.Complete(sources.AllSources)
+}
+
+func setIfNotZero[T comparable](target *T, value T) {
+	var zero T
+	if value != zero {
+		*target = value
+	}
+}
+
+// setLimit keeps the default for zero value, negative value is 0 of the flag, which means no limit
+func setLimit[T int | time.Duration](target *T, value T) {
+	switch {
+	case value < 0:
+		*target = 0
+	case value > 0:
+		*target = value
+	}
+}
@@ -0,0 +1,146 @@
+package getversion
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/scylladb-actions/get-version/sources/maven/maventest"
+	"github.com/scylladb-actions/get-version/version"
+)
+
+func TestQueryRun(t *testing.T) {
+	server := maventest.NewServer()
+	defer server.Close()
+
+	result, err := Query{
+		Source: SourceMavenArtifact,
+		Filter: "4.*.*",
+		Maven:  MavenOptions{Group: maventest.Group, ArtifactID: maventest.ArtifactID, URL: server.URL},
+	}.Run(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := []string{"4.18.0.0", "4.17.0.1"}
+	if got := result.Versions.AsStringSlice(true); !slices.Equal(got, expected) {
+		t.Errorf("expected versions %v, got %v", expected, got)
+	}
+	if len(result.All) != 3 || len(result.Ignored) != 1 || result.Ignored[0].Version != "snapshot" {
+		t.Errorf("unexpected result %+v", result)
+	}
+	if latest, ok := result.Latest(); !ok || latest.String() != "4.18.0.0" {
+		t.Errorf("expected latest 4.18.0.0, got %q", latest.String())
+	}
+}
+
+func TestQueryRunFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "versions.yaml")
+	if err := os.WriteFile(path, []byte("- 6.2.1\n- 6.2.0\n- 6.1.5\n"), 0o644); err != nil {
+		t.Fatalf("failed to write versions: %v", err)
+	}
+	result, err := Query{Source: SourceFile, File: path, Filter: "LAST.LAST-1.LAST"}.Run(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := result.Versions.AsStringSlice(true); !slices.Equal(got, []string{"6.1.5"}) {
+		t.Errorf("unexpected versions %v", got)
+	}
+}
+
+func TestQueryRunErrors(t *testing.T) {
+	server := maventest.NewServer()
+	defer server.Close()
+
+	tcases := []struct {
+		name     string
+		query    Query
+		expected error
+	}{
+		{name: "invalid filter", query: Query{Source: SourceMavenArtifact, Filter: "LAST.("},
+			expected: ErrInvalidFilter},
+		{name: "no source", query: Query{}, expected: ErrInvalidQuery},
+		{name: "unknown source", query: Query{Source: "pypi"}, expected: ErrInvalidQuery},
+		{name: "source error", query: Query{
+			Source: SourceMavenArtifact,
+			Maven:  MavenOptions{Group: maventest.Group, ArtifactID: "unknown", URL: server.URL},
+			HTTP:   HTTPOptions{RetryMax: -1},
+		}, expected: ErrSource},
+	}
+
+	for _, tcase := range tcases {
+		t.Run(tcase.name, func(t *testing.T) {
+			if _, err := tcase.query.Run(context.Background()); !errors.Is(err, tcase.expected) {
+				t.Errorf("expected %v, got %v", tcase.expected, err)
+			}
+		})
+	}
+}
+
+func TestQueryParams(t *testing.T) {
+	p, err := Query{
+		Source: SourceGitHubTag,
+		Repo:   "scylladb/scylla",
+		GitHub: GitHubOptions{API: GitHubAPIREST},
+		HTTP:   HTTPOptions{RequestTimeout: -1, Concurrency: 2},
+	}.params()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if p.RequestTimeout != 0 || p.Concurrency != 2 || p.RetryMax != 5 || p.RetryMaxDelay != 30000 {
+		t.Errorf("unexpected http params %+v", p)
+	}
+	if p.MavenURL == "" || p.DockerHubURL == "" || p.CacheTTL == 0 || p.GitHubAPI != GitHubAPIREST {
+		t.Errorf("defaults are not applied: %+v", p)
+	}
+}
+
+func TestQueryLog(t *testing.T) {
+	server := maventest.NewServer()
+	defer server.Close()
+
+	var log bytes.Buffer
+	_, err := Query{
+		Source: SourceMavenArtifact,
+		Maven:  MavenOptions{Group: maventest.Group, ArtifactID: maventest.ArtifactID, URL: server.URL},
+		HTTP:   HTTPOptions{Insecure: true},
+		Log:    &log,
+	}.Run(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(log.String(), "--insecure") {
+		t.Errorf("expected the warning to be written to Log, got %q", log.String())
+	}
+
+	p, err := Query{Source: SourceMavenArtifact, Maven: MavenOptions{Group: "g", ArtifactID: "a"}}.params()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if p.LogOutput() != io.Discard {
+		t.Errorf("expected warnings to be discarded when Log is not set, got %v", p.LogOutput())
+	}
+}
+
+func TestResultLatest(t *testing.T) {
+	for _, versions := range [][]string{
+		{"6.2.0-rc1", "6.1.5", "6.2.0-rc2"},
+		{"6.2.0-rc2", "6.2.0-rc1", "6.1.5"},
+	} {
+		var result Result
+		for _, value := range versions {
+			result.Versions = append(result.Versions, version.NewMust(value))
+		}
+		if latest, ok := result.Latest(); !ok || latest.String() != "6.2.0-rc2" {
+			t.Errorf("expected latest 6.2.0-rc2 of %v, got %q", versions, latest.String())
+		}
+	}
+	if _, ok := (Result{}).Latest(); ok {
+		t.Errorf("expected no latest version of no versions")
+	}
+}
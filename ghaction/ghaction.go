@@ -0,0 +1,66 @@
+// Package ghaction talks to GitHub Actions runner through workflow commands and job summary.
+package ghaction
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// commandOutput is where workflow commands are written, the runner reads them from stderr as well as from stdout,
+// and stdout is kept for versions.
+var commandOutput io.Writer = os.Stderr
+
+// Running tells whether the tool runs in a GitHub Actions job.
+func Running() bool {
+	return os.Getenv("GITHUB_ACTIONS") == "true"
+}
+
+// escapeData escapes message of a workflow command, so that it stays on a single line
+func escapeData(value string) string {
+	return strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A").Replace(value)
+}
+
+func command(name, message string) {
+	_, _ = fmt.Fprintf(commandOutput, "::%s::%s\n", name, escapeData(message))
+}
+
+// Mask tells the runner to mask the value in logs, it is used for tokens that are obtained at runtime,
+// since the runner only masks secrets it knows about. The command is written to w, which is the log of sources,
+// nothing is written outside of GitHub Actions.
+func Mask(w io.Writer, value string) {
+	if value == "" || !Running() {
+		return
+	}
+	_, _ = fmt.Fprintf(w, "::add-mask::%s\n", escapeData(value))
+}
+
+// Warning creates a warning annotation.
+func Warning(message string) {
+	command("warning", message)
+}
+
+// Error creates an error annotation.
+func Error(message string) {
+	command("error", message)
+}
+
+// WriteSummary appends markdown to the job summary in $GITHUB_STEP_SUMMARY.
+func WriteSummary(markdown string) error {
+	path := os.Getenv("GITHUB_STEP_SUMMARY")
+	if path == "" {
+		return fmt.Errorf("GITHUB_STEP_SUMMARY is not set")
+	}
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to open job summary %q: %w", path, err)
+	}
+	defer func() {
+		_ = file.Close()
+	}()
+	if _, err = file.WriteString(markdown); err != nil {
+		return fmt.Errorf("failed to write job summary %q: %w", path, err)
+	}
+	return nil
+}
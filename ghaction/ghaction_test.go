@@ -0,0 +1,91 @@
+package ghaction
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/scylladb-actions/get-version/types"
+	"github.com/scylladb-actions/get-version/version"
+)
+
+func captureCommands(t *testing.T) *bytes.Buffer {
+	t.Helper()
+	var buf bytes.Buffer
+	original := commandOutput
+	commandOutput = &buf
+	t.Cleanup(func() {
+		commandOutput = original
+	})
+	return &buf
+}
+
+func TestCommands(t *testing.T) {
+	buf := captureCommands(t)
+	Warning("2 versions\nwere ignored: 100%")
+	Error("no versions")
+	expected := "::warning::2 versions%0Awere ignored: 100%25\n::error::no versions\n"
+	if buf.String() != expected {
+		t.Fatalf("expected %q, got %q", expected, buf.String())
+	}
+}
+
+func TestMask(t *testing.T) {
+	var buf bytes.Buffer
+
+	t.Setenv("GITHUB_ACTIONS", "")
+	Mask(&buf, "secret-token")
+	if buf.Len() != 0 {
+		t.Fatalf("expected nothing to be written outside of GitHub Actions, got %q", buf.String())
+	}
+
+	t.Setenv("GITHUB_ACTIONS", "true")
+	Mask(&buf, "secret-token")
+	Mask(&buf, "")
+	if expected := "::add-mask::secret-token\n"; buf.String() != expected {
+		t.Fatalf("expected %q, got %q", expected, buf.String())
+	}
+}
+
+func TestSummary(t *testing.T) {
+	withMetadata := version.NewMust("6.2.1")
+	withMetadata.SetMetadata(version.Metadata{"commit": "abc", "date": "2024-11-01"})
+	summary := Summary{
+		Source: types.GitHubTag,
+		Query:  "scylladb/scylla",
+		Filter: "LAST.LAST.*",
+		Found:  3,
+		Ignored: []types.IgnoredVersion{
+			{Version: "branch|point", Reason: errors.New("can't convert major \"branch|point\" to int")},
+		},
+		Selected: version.Versions{version.NewMust("6.2.0"), withMetadata},
+	}
+
+	path := filepath.Join(t.TempDir(), "summary.md")
+	if err := os.WriteFile(path, []byte("previous step\n"), 0o600); err != nil {
+		t.Fatalf("failed to create summary: %v", err)
+	}
+	t.Setenv("GITHUB_STEP_SUMMARY", path)
+	if err := WriteSummary(summary.Markdown()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read summary: %v", err)
+	}
+
+	for _, expected := range []string{
+		"previous step\n### get-version: `github-tag` `scylladb/scylla`\n",
+		"Filter `LAST.LAST.*` selected **2** of 3 versions, 1 ignored\n",
+		"| Version | commit | date |\n|---|---|---|\n| 6.2.1 | abc | 2024-11-01 |\n| 6.2.0 |  |  |\n",
+		"<details><summary>Ignored versions (1)</summary>",
+		`| branch\|point | can't convert major "branch\|point" to int |`,
+	} {
+		if !strings.Contains(string(data), expected) {
+			t.Errorf("expected summary to contain %q, got:\n%s", expected, data)
+		}
+	}
+}
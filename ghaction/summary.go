@@ -0,0 +1,87 @@
+package ghaction
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/scylladb-actions/get-version/types"
+	"github.com/scylladb-actions/get-version/version"
+)
+
+// summaryIgnoredLimit keeps job summary readable and below its 1MiB limit for repositories with thousands of tags
+const summaryIgnoredLimit = 100
+
+// Summary is what the tool saw and selected, it is rendered to the job summary.
+type Summary struct {
+	Source   types.SourceName
+	Query    string
+	Filter   string
+	Found    int
+	Ignored  []types.IgnoredVersion
+	Selected version.Versions
+	NoPrefix bool
+}
+
+// escapeCell escapes text for a markdown table cell
+func escapeCell(value string) string {
+	return strings.NewReplacer("|", `\|`, "\r", " ", "\n", " ").Replace(value)
+}
+
+func (s Summary) versionString(v version.Version) string {
+	if s.NoPrefix {
+		return v.NoPrefixString()
+	}
+	return v.String()
+}
+
+// Markdown renders a table of selected versions, with a column per metadata key, and a list of ignored versions.
+func (s Summary) Markdown() string {
+	var b strings.Builder
+	filter := s.Filter
+	if filter == "" {
+		filter = "none"
+	}
+	_, _ = fmt.Fprintf(&b, "### get-version: `%s` `%s`\n\n", s.Source, s.Query)
+	_, _ = fmt.Fprintf(&b, "Filter `%s` selected **%d** of %d versions, %d ignored\n\n",
+		filter, len(s.Selected), s.Found, len(s.Ignored))
+
+	if len(s.Selected) != 0 {
+		var keys []string
+		for _, ver := range s.Selected {
+			for key := range ver.Metadata() {
+				if !slices.Contains(keys, key) {
+					keys = append(keys, key)
+				}
+			}
+		}
+		slices.Sort(keys)
+
+		b.WriteString("| Version |")
+		for _, key := range keys {
+			_, _ = fmt.Fprintf(&b, " %s |", escapeCell(key))
+		}
+		b.WriteString("\n|---|" + strings.Repeat("---|", len(keys)) + "\n")
+		for _, ver := range slices.Clone(s.Selected).Order(true) {
+			_, _ = fmt.Fprintf(&b, "| %s |", escapeCell(s.versionString(ver)))
+			for _, key := range keys {
+				_, _ = fmt.Fprintf(&b, " %s |", escapeCell(ver.Metadata()[key]))
+			}
+			b.WriteString("\n")
+		}
+		b.WriteString("\n")
+	}
+
+	if len(s.Ignored) != 0 {
+		_, _ = fmt.Fprintf(&b, "<details><summary>Ignored versions (%d)</summary>\n\n", len(s.Ignored))
+		b.WriteString("| Version | Reason |\n|---|---|\n")
+		for _, ignored := range s.Ignored[:min(len(s.Ignored), summaryIgnoredLimit)] {
+			_, _ = fmt.Fprintf(&b, "| %s | %s |\n", escapeCell(ignored.Version), escapeCell(ignored.Reason.Error()))
+		}
+		if len(s.Ignored) > summaryIgnoredLimit {
+			_, _ = fmt.Fprintf(&b, "\nand %d more\n", len(s.Ignored)-summaryIgnoredLimit)
+		}
+		b.WriteString("\n</details>\n\n")
+	}
+	return b.String()
+}
@@ -0,0 +1,249 @@
+package httpclient
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/scylladb-actions/get-version/types"
+)
+
+// ErrNotCached is returned in offline mode for requests that have no cached response.
+var ErrNotCached = errors.New("response is not cached")
+
+type cacheableKey struct{}
+
+// MarkCacheable marks a request with body, like GraphQL query, as safe to cache by its body,
+// only GET requests are cached otherwise.
+func MarkCacheable(rq *http.Request) *http.Request {
+	return rq.WithContext(context.WithValue(rq.Context(), cacheableKey{}, true))
+}
+
+func isCacheable(rq *http.Request) bool {
+	if rq.Method == http.MethodGet {
+		return true
+	}
+	cacheable, _ := rq.Context().Value(cacheableKey{}).(bool)
+	return cacheable
+}
+
+// CacheDir returns directory of the response cache, --cache-dir or get-version directory
+// in user cache directory, which is $XDG_CACHE_HOME on Linux.
+func CacheDir(p types.Params) (string, error) {
+	if p.CacheDir != "" {
+		return p.CacheDir, nil
+	}
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to find cache directory, provide --cache-dir: %w", err)
+	}
+	return filepath.Join(dir, "get-version"), nil
+}
+
+type cacheEntry struct {
+	URL      string
+	Status   int
+	Header   http.Header
+	Body     []byte
+	StoredAt time.Time
+}
+
+func (e cacheEntry) response(rq *http.Request) *http.Response {
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", e.Status, http.StatusText(e.Status)),
+		StatusCode:    e.Status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        e.Header.Clone(),
+		Body:          io.NopCloser(bytes.NewReader(e.Body)),
+		ContentLength: int64(len(e.Body)),
+		Request:       rq,
+	}
+}
+
+// cacheTransport stores successful responses on disk, serves them while they are fresh
+// and revalidates them with ETag and Last-Modified afterwards.
+// GitHub does not count 304 Not Modified responses against the rate limit.
+type cacheTransport struct {
+	base    http.RoundTripper
+	dir     string
+	ttl     time.Duration
+	offline bool
+	now     func() time.Time
+	log     io.Writer
+}
+
+func newCacheTransport(base http.RoundTripper, p types.Params) (http.RoundTripper, error) {
+	dir, err := CacheDir(p)
+	if err != nil {
+		return nil, err
+	}
+	if err = os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create cache directory: %w", err)
+	}
+	return cacheTransport{
+		base:    base,
+		dir:     dir,
+		ttl:     p.CacheTTL,
+		offline: p.Offline,
+		now:     time.Now,
+		log:     p.LogOutput(),
+	}, nil
+}
+
+// requestKey identifies a request by method, URL, Accept header and body
+func requestKey(rq *http.Request) (string, error) {
+	h := sha256.New()
+	_, _ = fmt.Fprintf(h, "%s %s\n%s\n", rq.Method, rq.URL, rq.Header.Get("Accept"))
+	if rq.GetBody != nil {
+		body, err := rq.GetBody()
+		if err != nil {
+			return "", err
+		}
+		_, err = io.Copy(h, body)
+		_ = body.Close()
+		if err != nil {
+			return "", err
+		}
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// entryPath names the entry by request and whether it is authenticated, so that responses fetched with a token,
+// like tags of a private repository, are not served to requests without one. The URL already names the host
+// and the repo, the token itself is not a part of the key, since GITHUB_TOKEN and App installation tokens
+// change with every job and the cache would never be hit.
+func (t cacheTransport) entryPath(rq *http.Request) (string, error) {
+	key, err := requestKey(rq)
+	if err != nil {
+		return "", err
+	}
+	if rq.Header.Get("Authorization") != "" {
+		sum := sha256.Sum256([]byte(key + "\nauthenticated"))
+		key = hex.EncodeToString(sum[:])
+	}
+	return filepath.Join(t.dir, key+".json"), nil
+}
+
+func (t cacheTransport) load(path string) (cacheEntry, bool) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return cacheEntry{}, false
+	}
+	var entry cacheEntry
+	if err = json.Unmarshal(data, &entry); err != nil {
+		return cacheEntry{}, false
+	}
+	return entry, true
+}
+
+func (t cacheTransport) store(path string, entry cacheEntry) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(path, data)
+}
+
+// writeFileAtomic writes file and renames it into place, so that concurrent readers never see partial files
+func writeFileAtomic(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".entry-*")
+	if err != nil {
+		return err
+	}
+	_, err = tmp.Write(data)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), path)
+	}
+	if err != nil {
+		_ = os.Remove(tmp.Name())
+	}
+	return err
+}
+
+func (t cacheTransport) RoundTrip(rq *http.Request) (*http.Response, error) {
+	if !isCacheable(rq) {
+		if t.offline {
+			return nil, fmt.Errorf("%w: %s %s can't be executed in offline mode", ErrNotCached, rq.Method, rq.URL)
+		}
+		return t.base.RoundTrip(rq)
+	}
+	path, err := t.entryPath(rq)
+	if err != nil {
+		return nil, err
+	}
+	entry, cached := t.load(path)
+	if t.offline {
+		if !cached {
+			return nil, fmt.Errorf("%w: %s %s", ErrNotCached, rq.Method, rq.URL)
+		}
+		return entry.response(rq), nil
+	}
+	if cached && t.now().Sub(entry.StoredAt) < t.ttl {
+		return entry.response(rq), nil
+	}
+
+	if cached {
+		etag, lastModified := entry.Header.Get("ETag"), entry.Header.Get("Last-Modified")
+		if etag != "" || lastModified != "" {
+			rq = rq.Clone(rq.Context())
+			if etag != "" {
+				rq.Header.Set("If-None-Match", etag)
+			}
+			if lastModified != "" {
+				rq.Header.Set("If-Modified-Since", lastModified)
+			}
+		}
+	}
+
+	resp, err := t.base.RoundTrip(rq)
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case resp.StatusCode == http.StatusNotModified && cached:
+		_ = resp.Body.Close()
+		entry.StoredAt = t.now()
+		t.storeOrWarn(path, entry)
+		return entry.response(rq), nil
+	case resp.StatusCode == http.StatusOK:
+		body, err := io.ReadAll(resp.Body)
+		_ = resp.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		t.storeOrWarn(path, cacheEntry{
+			URL:      rq.URL.String(),
+			Status:   resp.StatusCode,
+			Header:   resp.Header,
+			Body:     body,
+			StoredAt: t.now(),
+		})
+		resp.Body = io.NopCloser(bytes.NewReader(body))
+		return resp, nil
+	default:
+		return resp, nil
+	}
+}
+
+// storeOrWarn does not fail the request, a broken cache should only make the tool slower
+func (t cacheTransport) storeOrWarn(path string, entry cacheEntry) {
+	if err := t.store(path, entry); err != nil {
+		_, _ = fmt.Fprintf(t.log, "failed to store response for %s in cache: %s\n",
+			strings.SplitN(entry.URL, "?", 2)[0], err)
+	}
+}
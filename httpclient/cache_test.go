@@ -0,0 +1,198 @@
+package httpclient
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestCacheTransport(t *testing.T) {
+	calls, revalidated := 0, 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			revalidated++
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", `"v1"`)
+		_, _ = w.Write([]byte(`["1.0.0"]`))
+	}))
+	defer server.Close()
+
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	transport := cacheTransport{
+		base: http.DefaultTransport,
+		dir:  t.TempDir(),
+		ttl:  time.Minute,
+		now:  func() time.Time { return now },
+		log:  io.Discard,
+	}
+	get := func(transport cacheTransport) (string, error) {
+		t.Helper()
+		resp, err := (&http.Client{Transport: transport}).Get(server.URL + "/tags?page=1")
+		if err != nil {
+			return "", err
+		}
+		defer func() {
+			_ = resp.Body.Close()
+		}()
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("expected status 200, got %s", resp.Status)
+		}
+		body, err := io.ReadAll(resp.Body)
+		return string(body), err
+	}
+
+	for _, step := range []struct {
+		name                string
+		advance             time.Duration
+		expectedCalls       int
+		expectedRevalidated int
+	}{
+		{name: "first request goes to the server", expectedCalls: 1},
+		{name: "fresh response is served from cache", advance: 30 * time.Second, expectedCalls: 1},
+		{name: "stale response is revalidated", advance: time.Minute, expectedCalls: 2, expectedRevalidated: 1},
+		{name: "revalidated response is fresh again", advance: 30 * time.Second, expectedCalls: 2, expectedRevalidated: 1},
+	} {
+		now = now.Add(step.advance)
+		body, err := get(transport)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", step.name, err)
+		}
+		if body != `["1.0.0"]` {
+			t.Fatalf("%s: unexpected body %q", step.name, body)
+		}
+		if calls != step.expectedCalls || revalidated != step.expectedRevalidated {
+			t.Fatalf("%s: expected %d calls and %d revalidations, got %d and %d",
+				step.name, step.expectedCalls, step.expectedRevalidated, calls, revalidated)
+		}
+	}
+
+	offline := transport
+	offline.offline = true
+	now = now.Add(time.Hour)
+	if _, err := get(offline); err != nil {
+		t.Fatalf("expected stale response to be served in offline mode, got %v", err)
+	}
+	if calls != 2 {
+		t.Fatalf("expected no requests in offline mode, got %d", calls-2)
+	}
+	_, err := (&http.Client{Transport: offline}).Get(server.URL + "/tags?page=2")
+	if !errors.Is(err, ErrNotCached) {
+		t.Fatalf("expected ErrNotCached for missing response, got %v", err)
+	}
+}
+
+func TestCacheTransportPOST(t *testing.T) {
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		calls++
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	cl := &http.Client{Transport: cacheTransport{
+		base: http.DefaultTransport,
+		dir:  t.TempDir(),
+		ttl:  time.Minute,
+		now:  time.Now,
+		log:  io.Discard,
+	}}
+	post := func(body string, cacheable bool) {
+		t.Helper()
+		rq, err := http.NewRequest(http.MethodPost, server.URL, bytes.NewReader([]byte(body)))
+		if err != nil {
+			t.Fatalf("failed to create request: %v", err)
+		}
+		if cacheable {
+			rq = MarkCacheable(rq)
+		}
+		resp, err := cl.Do(rq)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		_ = resp.Body.Close()
+	}
+
+	post(`{"token":"secret"}`, false)
+	post(`{"token":"secret"}`, false)
+	if calls != 2 {
+		t.Fatalf("expected POST requests not to be cached, got %d calls", calls)
+	}
+	post(`{"cursor":"a"}`, true)
+	post(`{"cursor":"a"}`, true)
+	post(`{"cursor":"b"}`, true)
+	if calls != 4 {
+		t.Fatalf("expected marked POST requests to be cached by body, got %d calls", calls-2)
+	}
+}
+
+func TestCacheTransportCredentials(t *testing.T) {
+	var hits atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		if r.Header.Get("Authorization") == "" {
+			http.Error(w, "not found", http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write([]byte("tags for " + r.Header.Get("Authorization")))
+	}))
+	defer server.Close()
+
+	cl := &http.Client{Transport: cacheTransport{
+		base: http.DefaultTransport,
+		dir:  t.TempDir(),
+		ttl:  time.Minute,
+		now:  time.Now,
+		log:  io.Discard,
+	}}
+	get := func(auth string) (int, string) {
+		t.Helper()
+		rq, err := http.NewRequest(http.MethodGet, server.URL+"/repos/private/tags", nil)
+		if err != nil {
+			t.Fatalf("failed to create request: %v", err)
+		}
+		if auth != "" {
+			rq.Header.Set("Authorization", auth)
+		}
+		resp, err := cl.Do(rq)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		defer func() {
+			_ = resp.Body.Close()
+		}()
+		body, err := io.ReadAll(resp.Body)
+		if err != nil {
+			t.Fatalf("failed to read body: %v", err)
+		}
+		return resp.StatusCode, string(body)
+	}
+
+	// Tokens change between jobs, requests with any token share one entry
+	for _, step := range []struct {
+		auth         string
+		expectedCode int
+		expectedBody string
+		expectedHits int32
+	}{
+		{auth: "Bearer token-a", expectedCode: http.StatusOK, expectedBody: "tags for Bearer token-a", expectedHits: 1},
+		{auth: "Bearer token-b", expectedCode: http.StatusOK, expectedBody: "tags for Bearer token-a", expectedHits: 1},
+		{auth: "", expectedCode: http.StatusNotFound, expectedBody: "not found\n", expectedHits: 2},
+		{auth: "Bearer token-c", expectedCode: http.StatusOK, expectedBody: "tags for Bearer token-a", expectedHits: 2},
+	} {
+		code, body := get(step.auth)
+		if code != step.expectedCode || body != step.expectedBody {
+			t.Errorf("%q: expected %d %q, got %d %q", step.auth, step.expectedCode, step.expectedBody, code, body)
+		}
+		if got := hits.Load(); got != step.expectedHits {
+			t.Errorf("%q: expected %d requests to server, got %d", step.auth, step.expectedHits, got)
+		}
+	}
+}
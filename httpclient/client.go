@@ -1,19 +1,54 @@
 package httpclient
 
 import (
-	"crypto/tls"
 	"net/http"
 
 	"github.com/scylladb-actions/get-version/types"
 )
 
-func New(p types.Params) *http.Client {
-	if p.SSLVerify {
-		return http.DefaultClient
+// New builds http client for sources, with --http-replay it serves responses only from recorded fixtures.
+// A client set in params is returned as it is.
+func New(p types.Params) (*http.Client, error) {
+	if p.HTTPClient != nil {
+		return p.HTTPClient, nil
 	}
-	httpClient := *http.DefaultClient
-	httpClient.Transport = &http.Transport{
-		TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+	if p.HTTPReplay != "" {
+		transport, err := newReplayTransport(p.HTTPReplay)
+		if err != nil {
+			return nil, err
+		}
+		return &http.Client{Transport: transport}, nil
 	}
-	return &httpClient
+	tlsConfig, err := NewTLSConfig(p)
+	if err != nil {
+		return nil, err
+	}
+	var networkConfig NetworkConfig
+	if p.NetworkConfig != "" {
+		if networkConfig, err = LoadNetworkConfig(p.NetworkConfig); err != nil {
+			return nil, err
+		}
+	}
+	transport, err := newTransport(tlsConfig, networkConfig)
+	if err != nil {
+		return nil, err
+	}
+	transport = retryTransport{
+		base:   timeoutTransport{base: transport, timeout: p.RequestTimeout},
+		policy: NewRetryPolicy(p),
+	}
+	if p.RateLimits != nil {
+		transport = rateLimitTransport{base: transport, limits: p.RateLimits, policy: NewRetryPolicy(p)}
+	}
+	if p.Cache {
+		if transport, err = newCacheTransport(transport, p); err != nil {
+			return nil, err
+		}
+	}
+	if p.HTTPRecord != "" {
+		if transport, err = newRecordTransport(transport, p.HTTPRecord); err != nil {
+			return nil, err
+		}
+	}
+	return &http.Client{Transport: transport}, nil
 }
@@ -0,0 +1,153 @@
+package httpclient
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ErrNoFixture is returned in replay mode for requests that have no recorded fixture.
+var ErrNoFixture = errors.New("no recorded fixture for request")
+
+// redacted replaces tokens in recorded responses of auth endpoints, like Docker Hub and GitHub App tokens
+const redacted = "REDACTED"
+
+// tokenFields are fields of JSON responses that hold credentials
+var tokenFields = []string{"token", "access_token", "refresh_token"}
+
+// fixture is a recorded response, it is stored as indented JSON to be reviewable in tests.
+// Request body is not stored since it may carry credentials, it is only a part of the fixture name.
+type fixture struct {
+	Method string      `json:"method"`
+	URL    string      `json:"url"`
+	Status int         `json:"status"`
+	Header http.Header `json:"header,omitempty"`
+	Body   string      `json:"body"`
+}
+
+func (f fixture) response(rq *http.Request) *http.Response {
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", f.Status, http.StatusText(f.Status)),
+		StatusCode:    f.Status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        f.Header.Clone(),
+		Body:          io.NopCloser(strings.NewReader(f.Body)),
+		ContentLength: int64(len(f.Body)),
+		Request:       rq,
+	}
+}
+
+// fixtureTransport records responses to fixture files when base is set and replays them
+// without network access otherwise.
+type fixtureTransport struct {
+	base http.RoundTripper
+	dir  string
+}
+
+func newRecordTransport(base http.RoundTripper, dir string) (http.RoundTripper, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create fixtures directory: %w", err)
+	}
+	return fixtureTransport{base: base, dir: dir}, nil
+}
+
+func newReplayTransport(dir string) (http.RoundTripper, error) {
+	if _, err := os.Stat(dir); err != nil {
+		return nil, fmt.Errorf("failed to open fixtures directory: %w", err)
+	}
+	return fixtureTransport{dir: dir}, nil
+}
+
+// fixturePath names fixture by host, to make fixture directories easy to browse, and by request key
+func (t fixtureTransport) fixturePath(rq *http.Request) (string, error) {
+	key, err := requestKey(rq)
+	if err != nil {
+		return "", err
+	}
+	host := strings.ReplaceAll(rq.URL.Host, ":", "_")
+	return filepath.Join(t.dir, fmt.Sprintf("%s-%s-%s.json", host, strings.ToLower(rq.Method), key[:16])), nil
+}
+
+func (t fixtureTransport) RoundTrip(rq *http.Request) (*http.Response, error) {
+	path, err := t.fixturePath(rq)
+	if err != nil {
+		return nil, err
+	}
+	if t.base == nil {
+		return t.replay(rq, path)
+	}
+
+	resp, err := t.base.RoundTrip(rq)
+	if err != nil {
+		return nil, err
+	}
+	body, err := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
+	header := resp.Header.Clone()
+	header.Del("Set-Cookie")
+	data, err := json.MarshalIndent(fixture{
+		Method: rq.Method,
+		URL:    rq.URL.String(),
+		Status: resp.StatusCode,
+		Header: header,
+		Body:   string(redactTokens(body)),
+	}, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	if err = writeFileAtomic(path, append(data, '\n')); err != nil {
+		return nil, fmt.Errorf("failed to record fixture for %s %s: %w", rq.Method, rq.URL, err)
+	}
+	return resp, nil
+}
+
+// redactTokens replaces credentials in a JSON object, other bodies are returned as they are
+func redactTokens(body []byte) []byte {
+	var object map[string]any
+	if err := json.Unmarshal(body, &object); err != nil {
+		return body
+	}
+	found := false
+	for _, field := range tokenFields {
+		if _, ok := object[field]; ok {
+			object[field] = redacted
+			found = true
+		}
+	}
+	if !found {
+		return body
+	}
+	out, err := json.Marshal(object)
+	if err != nil {
+		return body
+	}
+	return out
+}
+
+func (t fixtureTransport) replay(rq *http.Request, path string) (*http.Response, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("%w: %s %s, expected it in %s", ErrNoFixture, rq.Method, rq.URL, path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read fixture: %w", err)
+	}
+	var f fixture
+	if err = json.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("failed to parse fixture %s: %w", path, err)
+	}
+	return f.response(rq), nil
+}
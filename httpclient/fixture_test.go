@@ -0,0 +1,142 @@
+package httpclient
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/scylladb-actions/get-version/types"
+)
+
+func TestRecordAndReplay(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		w.Header().Set("Link", `<next>; rel="next"`)
+		w.Header().Set("Set-Cookie", "session=secret")
+		if r.URL.Path == "/missing" {
+			w.WriteHeader(http.StatusNotFound)
+		}
+		_, _ = w.Write([]byte(r.Method + " " + r.URL.Path + " " + string(body)))
+	}))
+	dir := t.TempDir()
+
+	type request struct {
+		method string
+		path   string
+		body   string
+	}
+	requests := []request{
+		{method: http.MethodGet, path: "/tags"},
+		{method: http.MethodGet, path: "/missing"},
+		{method: http.MethodPost, path: "/graphql", body: "page 1"},
+		{method: http.MethodPost, path: "/graphql", body: "page 2"},
+	}
+	do := func(cl *http.Client, rq request) (*http.Response, string, error) {
+		httpRq, err := http.NewRequest(rq.method, server.URL+rq.path, strings.NewReader(rq.body))
+		if err != nil {
+			t.Fatalf("failed to create request: %v", err)
+		}
+		resp, err := cl.Do(MarkCacheable(httpRq))
+		if err != nil {
+			return nil, "", err
+		}
+		defer func() {
+			_ = resp.Body.Close()
+		}()
+		body, err := io.ReadAll(resp.Body)
+		return resp, string(body), err
+	}
+
+	recorder, err := New(types.Params{HTTPRecord: dir})
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+	recorded := map[request]string{}
+	for _, rq := range requests {
+		_, body, err := do(recorder, rq)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		recorded[rq] = body
+	}
+	server.Close()
+
+	replayer, err := New(types.Params{HTTPReplay: dir})
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+	for _, rq := range requests {
+		resp, body, err := do(replayer, rq)
+		if err != nil {
+			t.Fatalf("unexpected error for %v: %v", rq, err)
+		}
+		if body != recorded[rq] {
+			t.Errorf("expected body %q for %v, got %q", recorded[rq], rq, body)
+		}
+		if rq.path == "/missing" && resp.StatusCode != http.StatusNotFound {
+			t.Errorf("expected status %d to be replayed, got %d", http.StatusNotFound, resp.StatusCode)
+		}
+		if link := resp.Header.Get("Link"); link != `<next>; rel="next"` {
+			t.Errorf("expected Link header to be replayed, got %q", link)
+		}
+		if cookie := resp.Header.Get("Set-Cookie"); cookie != "" {
+			t.Errorf("expected Set-Cookie header not to be recorded, got %q", cookie)
+		}
+	}
+
+	_, _, err = do(replayer, request{method: http.MethodGet, path: "/not-recorded"})
+	if !errors.Is(err, ErrNoFixture) {
+		t.Fatalf("expected ErrNoFixture, got %v", err)
+	}
+}
+
+func TestRecordRedactsTokens(t *testing.T) {
+	tokens := map[string]string{
+		"/v2/auth/token": `{"access_token":"dckr_secret",` +
+			`"refresh_token":"dckr_refresh","token":"dckr_jwt"}`,
+		"/app/installations/1/access_tokens": `{"token":"ghs_secret","expires_at":"2024-01-01T01:00:00Z"}`,
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(tokens[r.URL.Path]))
+	}))
+	defer server.Close()
+	dir := t.TempDir()
+
+	recorder, err := New(types.Params{HTTPRecord: dir})
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+	for path := range tokens {
+		resp, err := recorder.Post(server.URL+path, "application/json", nil)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		body, _ := io.ReadAll(resp.Body)
+		_ = resp.Body.Close()
+		if string(body) != tokens[path] {
+			t.Errorf("expected response to be returned as it is, got %q", body)
+		}
+	}
+
+	files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil || len(files) != len(tokens) {
+		t.Fatalf("expected %d fixtures, got %v: %v", len(tokens), files, err)
+	}
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatalf("failed to read fixture: %v", err)
+		}
+		if strings.Contains(string(data), "secret") || strings.Contains(string(data), "dckr_") {
+			t.Errorf("expected tokens to be redacted in %s, got:\n%s", file, data)
+		}
+		if !strings.Contains(string(data), redacted) {
+			t.Errorf("expected %s to have redacted tokens, got:\n%s", file, data)
+		}
+	}
+}
@@ -0,0 +1,182 @@
+package httpclient
+
+import (
+	"bytes"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// proxyNone disables proxy for a host even if it is set by environment or globally
+const proxyNone = "none"
+
+// NetworkConfig is loaded from --network-config file:
+//
+//	proxy: http://proxy.internal:3128
+//	hosts:
+//	  hub.docker.com:
+//	    mirror: https://nexus.internal/repository/dockerhub
+//	    ca-bundle: /etc/ssl/internal-ca.pem
+//	    auth-header: Bearer ${NEXUS_TOKEN}
+//	  "*.github.com":
+//	    proxy: none
+//
+// Requests use proxy from HTTPS_PROXY/HTTP_PROXY/NO_PROXY env variables unless proxy is set
+// globally or for the host.
+type NetworkConfig struct {
+	Proxy string                `yaml:"proxy"`
+	Hosts map[string]HostConfig `yaml:"hosts"`
+}
+
+// HostConfig overrides network settings for requests to a host, the host is matched
+// before it is rewritten to the mirror.
+type HostConfig struct {
+	// Proxy is URL of the proxy for the host, or "none" to connect directly
+	Proxy string `yaml:"proxy"`
+	// CABundle is PEM file or directory with CA certificates to trust in addition to system ones
+	CABundle string `yaml:"ca-bundle"`
+	// AuthHeader replaces Authorization header of requests, env variables are expanded in it
+	AuthHeader string `yaml:"auth-header"`
+	// Mirror is URL that replaces scheme and host of requests and prefixes their path
+	Mirror string `yaml:"mirror"`
+}
+
+func LoadNetworkConfig(path string) (NetworkConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return NetworkConfig{}, fmt.Errorf("failed to read network config: %w", err)
+	}
+	var cfg NetworkConfig
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err = dec.Decode(&cfg); err != nil && !errors.Is(err, io.EOF) {
+		return NetworkConfig{}, fmt.Errorf("failed to parse network config %q: %w", path, err)
+	}
+	return cfg, nil
+}
+
+func parseProxy(value string) (func(*http.Request) (*url.URL, error), error) {
+	switch value {
+	case "":
+		return http.ProxyFromEnvironment, nil
+	case proxyNone:
+		return nil, nil
+	}
+	proxyURL, err := url.Parse(value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid proxy url %q: %w", value, err)
+	}
+	return http.ProxyURL(proxyURL), nil
+}
+
+type hostRoute struct {
+	transport  http.RoundTripper
+	authHeader string
+	mirror     *url.URL
+}
+
+// hostRouter sends requests through transports configured for their hosts.
+type hostRouter struct {
+	fallback http.RoundTripper
+	routes   map[string]hostRoute
+}
+
+// newTransport builds the transport for all requests, it is always based on http.DefaultTransport
+// to keep its connection settings.
+func newTransport(tlsConfig *tls.Config, cfg NetworkConfig) (http.RoundTripper, error) {
+	newHostTransport := func(proxy, caBundle string) (*http.Transport, error) {
+		transport := http.DefaultTransport.(*http.Transport).Clone()
+		transport.TLSClientConfig = tlsConfig.Clone()
+		var err error
+		if transport.Proxy, err = parseProxy(proxy); err != nil {
+			return nil, err
+		}
+		if caBundle != "" {
+			// CAs of the host are trusted in addition to --ca-bundle ones
+			rootCAs := transport.TLSClientConfig.RootCAs
+			if transport.TLSClientConfig.RootCAs, err = loadCABundle(rootCAs, caBundle); err != nil {
+				return nil, err
+			}
+		}
+		return transport, nil
+	}
+
+	fallback, err := newHostTransport(cfg.Proxy, "")
+	if err != nil {
+		return nil, err
+	}
+	if len(cfg.Hosts) == 0 {
+		return fallback, nil
+	}
+
+	router := hostRouter{fallback: fallback, routes: map[string]hostRoute{}}
+	for host, hostCfg := range cfg.Hosts {
+		route := hostRoute{transport: fallback, authHeader: os.ExpandEnv(hostCfg.AuthHeader)}
+		if hostCfg.Proxy != "" || hostCfg.CABundle != "" {
+			proxy := hostCfg.Proxy
+			if proxy == "" {
+				proxy = cfg.Proxy
+			}
+			if route.transport, err = newHostTransport(proxy, hostCfg.CABundle); err != nil {
+				return nil, fmt.Errorf("invalid network config for host %q: %w", host, err)
+			}
+		}
+		if hostCfg.Mirror != "" {
+			if route.mirror, err = url.Parse(hostCfg.Mirror); err != nil || route.mirror.Host == "" {
+				return nil, fmt.Errorf("invalid mirror url %q for host %q", hostCfg.Mirror, host)
+			}
+		}
+		router.routes[strings.ToLower(host)] = route
+	}
+	return router, nil
+}
+
+// route finds host config by exact host, host with port or wildcard like *.example.com.
+func (r hostRouter) route(u *url.URL) (hostRoute, bool) {
+	if route, ok := r.routes[strings.ToLower(u.Host)]; ok {
+		return route, true
+	}
+	hostname := strings.ToLower(u.Hostname())
+	if route, ok := r.routes[hostname]; ok {
+		return route, true
+	}
+	for domain := hostname; ; {
+		_, parent, ok := strings.Cut(domain, ".")
+		if !ok {
+			return hostRoute{}, false
+		}
+		if route, ok := r.routes["*."+parent]; ok {
+			return route, true
+		}
+		domain = parent
+	}
+}
+
+func (r hostRouter) RoundTrip(rq *http.Request) (*http.Response, error) {
+	route, ok := r.route(rq.URL)
+	if !ok {
+		return r.fallback.RoundTrip(rq)
+	}
+	if route.mirror == nil && route.authHeader == "" {
+		return route.transport.RoundTrip(rq)
+	}
+	rq = rq.Clone(rq.Context())
+	if route.mirror != nil {
+		rq.URL.Scheme = route.mirror.Scheme
+		rq.URL.Host = route.mirror.Host
+		rq.URL.Path = strings.TrimSuffix(route.mirror.Path, "/") + rq.URL.Path
+		rq.URL.RawPath = ""
+		rq.Host = ""
+	}
+	if route.authHeader != "" {
+		rq.Header.Set("Authorization", route.authHeader)
+	}
+	return route.transport.RoundTrip(rq)
+}
@@ -0,0 +1,96 @@
+package httpclient
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/scylladb-actions/get-version/types"
+)
+
+func writeNetworkConfig(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "network.yaml")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("failed to write network config: %v", err)
+	}
+	return path
+}
+
+func TestNetworkConfigMirror(t *testing.T) {
+	mirror := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/repository/dockerhub/v2/repositories/library/ubuntu/tags" {
+			t.Errorf("unexpected path %q", r.URL.Path)
+		}
+		if r.URL.RawQuery != "page_size=1000" {
+			t.Errorf("unexpected query %q", r.URL.RawQuery)
+		}
+		if auth := r.Header.Get("Authorization"); auth != "Bearer nexus-token" {
+			t.Errorf("unexpected Authorization header %q", auth)
+		}
+	}))
+	defer mirror.Close()
+
+	t.Setenv("NEXUS_TOKEN", "nexus-token")
+	cl, err := New(types.Params{NetworkConfig: writeNetworkConfig(t, `
+hosts:
+  "*.docker.com":
+    mirror: `+mirror.URL+`/repository/dockerhub/
+    auth-header: Bearer ${NEXUS_TOKEN}
+`)})
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+	resp, err := cl.Get("https://hub.docker.com/v2/repositories/library/ubuntu/tags?page_size=1000")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	_ = resp.Body.Close()
+}
+
+func TestNetworkConfigProxy(t *testing.T) {
+	proxied := map[string]bool{}
+	proxy := httptest.NewServer(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		// Proxy receives requests with absolute URLs of the target host
+		proxied[r.URL.Host] = true
+	}))
+	defer proxy.Close()
+	direct := httptest.NewServer(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}))
+	defer direct.Close()
+
+	cl, err := New(types.Params{NetworkConfig: writeNetworkConfig(t, `
+proxy: `+proxy.URL+`
+hosts:
+  `+direct.Listener.Addr().String()+`:
+    proxy: none
+`)})
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+	for _, target := range []string{"http://registry.example.invalid/v2/", direct.URL} {
+		resp, err := cl.Get(target)
+		if err != nil {
+			t.Fatalf("unexpected error for %s: %v", target, err)
+		}
+		_ = resp.Body.Close()
+	}
+	if !proxied["registry.example.invalid"] {
+		t.Errorf("expected request to be sent through the proxy")
+	}
+	if len(proxied) != 1 {
+		t.Errorf("expected only one host to be proxied, got %v", proxied)
+	}
+}
+
+func TestNetworkConfigUnknownField(t *testing.T) {
+	_, err := LoadNetworkConfig(writeNetworkConfig(t, `
+hosts:
+  hub.docker.com:
+    mirrror: https://nexus.internal
+`))
+	if err == nil {
+		t.Fatalf("expected error for unknown field")
+	}
+}
@@ -0,0 +1,160 @@
+package httpclient
+
+import (
+	"context"
+	"net/url"
+	"strconv"
+	"sync"
+
+	"github.com/scylladb-actions/get-version/types"
+	"github.com/scylladb-actions/get-version/version"
+)
+
+// pageParam is the query parameter both GitHub and Docker Hub use for page number
+const pageParam = "page"
+
+// Page is a single page of a paginated listing.
+type Page[T any] struct {
+	Value T
+	// Next is URL of the next page, empty for the last page
+	Next string
+	// LastPage is the number of the last page when API tells it, 0 otherwise
+	LastPage int
+}
+
+// VersionsPage holds versions found on a single page of a listing of versions.
+type VersionsPage struct {
+	Versions version.Versions
+	Ignored  []types.IgnoredVersion
+}
+
+// StopAfter returns a function that tells Paginate to stop once n versions are found,
+// it returns nil to fetch all pages when n is 0.
+func StopAfter(n int) func(VersionsPage) bool {
+	if n == 0 {
+		return nil
+	}
+	found := 0
+	return func(page VersionsPage) bool {
+		found += len(page.Versions)
+		return found >= n
+	}
+}
+
+// PageFetcher fetches a page by its URL.
+type PageFetcher[T any] func(ctx context.Context, url string) (Page[T], error)
+
+// PageNumber returns page number from page URL, 0 if URL has no page number.
+func PageNumber(rawURL string) int {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return 0
+	}
+	page, err := strconv.Atoi(u.Query().Get(pageParam))
+	if err != nil {
+		return 0
+	}
+	return page
+}
+
+// withPageNumber replaces page number in URL of another page of the same listing
+func withPageNumber(pageURL string, page int) string {
+	u, err := url.Parse(pageURL)
+	if err != nil {
+		return pageURL
+	}
+	query := u.Query()
+	query.Set(pageParam, strconv.Itoa(page))
+	u.RawQuery = query.Encode()
+	return u.String()
+}
+
+// Paginate fetches all pages starting from firstURL and returns their values in page order.
+// When the first page tells the number of the last page and its next link has page number,
+// the rest of pages are fetched by up to concurrency requests at once, otherwise next links are followed one by one.
+// If done is not nil pages are fetched one by one until done returns true for a page.
+func Paginate[T any](
+	ctx context.Context,
+	firstURL string,
+	concurrency int,
+	fetch PageFetcher[T],
+	done func(T) bool,
+) ([]T, error) {
+	first, err := fetch(ctx, firstURL)
+	if err != nil {
+		return nil, err
+	}
+	out := []T{first.Value}
+	if first.Next == "" || (done != nil && done(first.Value)) {
+		return out, nil
+	}
+	if done != nil {
+		concurrency = 1
+	}
+
+	nextPage := PageNumber(first.Next)
+	if concurrency > 1 && nextPage > 0 && first.LastPage > nextPage {
+		values, err := fetchConcurrently(ctx, first.Next, nextPage, first.LastPage, concurrency, fetch)
+		if err != nil {
+			return nil, err
+		}
+		return append(out, values...), nil
+	}
+
+	for next := first.Next; next != ""; {
+		page, err := fetch(ctx, next)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, page.Value)
+		if done != nil && done(page.Value) {
+			break
+		}
+		next = page.Next
+	}
+	return out, nil
+}
+
+func fetchConcurrently[T any](
+	ctx context.Context,
+	pageURL string,
+	fromPage, lastPage, concurrency int,
+	fetch PageFetcher[T],
+) ([]T, error) {
+	ctx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+
+	values := make([]T, lastPage-fromPage+1)
+	pages := make(chan int)
+	var wg sync.WaitGroup
+	for range min(concurrency, len(values)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for page := range pages {
+				result, err := fetch(ctx, withPageNumber(pageURL, page))
+				if err != nil {
+					cancel(err)
+					continue
+				}
+				values[page-fromPage] = result.Value
+			}
+		}()
+	}
+
+feed:
+	for page := fromPage; page <= lastPage; page++ {
+		select {
+		case pages <- page:
+		case <-ctx.Done():
+			break feed
+		}
+	}
+	close(pages)
+	wg.Wait()
+
+	if err := context.Cause(ctx); err != nil {
+		return nil, err
+	}
+	return values, nil
+}
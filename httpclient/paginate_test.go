@@ -0,0 +1,140 @@
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// pagedListing serves pages of numbers, page N holds N, tellsLast controls whether pages tell the last page
+type pagedListing struct {
+	pages     int
+	tellsLast bool
+	failPage  int
+
+	mu          sync.Mutex
+	requested   []int
+	inFlight    atomic.Int32
+	maxInFlight atomic.Int32
+}
+
+func (l *pagedListing) fetch(_ context.Context, rawURL string) (Page[int], error) {
+	inFlight := l.inFlight.Add(1)
+	defer l.inFlight.Add(-1)
+	for {
+		current := l.maxInFlight.Load()
+		if inFlight <= current || l.maxInFlight.CompareAndSwap(current, inFlight) {
+			break
+		}
+	}
+	// Let concurrent requests overlap and finish out of order
+	page := PageNumber(rawURL)
+	time.Sleep(time.Duration(l.pages-page) * time.Millisecond)
+
+	l.mu.Lock()
+	l.requested = append(l.requested, page)
+	l.mu.Unlock()
+	if page == l.failPage {
+		return Page[int]{}, fmt.Errorf("page %d failed", page)
+	}
+	out := Page[int]{Value: page}
+	if page < l.pages {
+		out.Next = fmt.Sprintf("https://example.com/items?page=%d&per_page=10", page+1)
+	}
+	if l.tellsLast {
+		out.LastPage = l.pages
+	}
+	return out, nil
+}
+
+func TestPaginate(t *testing.T) {
+	tcases := []struct {
+		name        string
+		tellsLast   bool
+		concurrency int
+		concurrent  bool
+	}{
+		{
+			name:        "concurrent when last page is known",
+			tellsLast:   true,
+			concurrency: 4,
+			concurrent:  true,
+		},
+		{
+			name:        "sequential when last page is unknown",
+			concurrency: 4,
+		},
+		{
+			name:        "sequential with concurrency 1",
+			tellsLast:   true,
+			concurrency: 1,
+		},
+	}
+
+	for _, tcase := range tcases {
+		t.Run(tcase.name, func(t *testing.T) {
+			listing := &pagedListing{pages: 10, tellsLast: tcase.tellsLast}
+			values, err := Paginate(context.Background(), "https://example.com/items?page=1&per_page=10",
+				tcase.concurrency, listing.fetch, nil)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			expected := []int{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}
+			if !slices.Equal(values, expected) {
+				t.Fatalf("expected pages in order %v, got %v", expected, values)
+			}
+			maxInFlight := int(listing.maxInFlight.Load())
+			if maxInFlight > tcase.concurrency {
+				t.Fatalf("expected at most %d requests at once, got %d", tcase.concurrency, maxInFlight)
+			}
+			if tcase.concurrent && maxInFlight < 2 {
+				t.Fatalf("expected pages to be fetched concurrently")
+			}
+			if !tcase.concurrent && maxInFlight != 1 {
+				t.Fatalf("expected pages to be fetched one by one, got %d requests at once", maxInFlight)
+			}
+		})
+	}
+}
+
+func TestPaginateStopsOnError(t *testing.T) {
+	listing := &pagedListing{pages: 50, tellsLast: true, failPage: 3}
+	_, err := Paginate(context.Background(), "https://example.com/items?page=1", 2,
+		func(ctx context.Context, rawURL string) (Page[int], error) {
+			if err := ctx.Err(); err != nil {
+				return Page[int]{}, err
+			}
+			return listing.fetch(ctx, rawURL)
+		}, nil)
+	if err == nil || err.Error() != "page 3 failed" {
+		t.Fatalf("expected error of the failed page, got %v", err)
+	}
+	if errors.Is(err, context.Canceled) {
+		t.Fatalf("expected the original error, got %v", err)
+	}
+	if len(listing.requested) == listing.pages {
+		t.Fatalf("expected remaining pages not to be fetched after error")
+	}
+}
+
+func TestPaginateDone(t *testing.T) {
+	listing := &pagedListing{pages: 10, tellsLast: true}
+	values, err := Paginate(context.Background(), "https://example.com/items?page=1", 4, listing.fetch,
+		func(page int) bool {
+			return page == 3
+		})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if expected := []int{1, 2, 3}; !slices.Equal(values, expected) {
+		t.Fatalf("expected pages %v, got %v", expected, values)
+	}
+	if expected := []int{1, 2, 3}; !slices.Equal(listing.requested, expected) {
+		t.Fatalf("expected only pages %v to be requested, got %v", expected, listing.requested)
+	}
+}
@@ -0,0 +1,53 @@
+package httpclient
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/scylladb-actions/get-version/types"
+)
+
+// RateLimitError is returned for requests to a host that is rate limited until the time
+type RateLimitError struct {
+	Host  string
+	Until time.Time
+}
+
+func (e *RateLimitError) Error() string {
+	return fmt.Sprintf("%s for %s until %s", ErrRateLimited, e.Host, e.Until.UTC().Format(time.RFC3339))
+}
+
+func (e *RateLimitError) Unwrap() error {
+	return ErrRateLimited
+}
+
+// rateLimitTransport records rate limits that retries did not get through in limits, which are shared by clients,
+// requests to a limited host fail with RateLimitError without reaching it until the limit is reset.
+// Rate limits that don't tell when they are reset are returned to the caller as they are.
+type rateLimitTransport struct {
+	base   http.RoundTripper
+	limits *types.RateLimits
+	policy RetryPolicy
+}
+
+func (t rateLimitTransport) RoundTrip(rq *http.Request) (*http.Response, error) {
+	now := t.policy.timeNow()
+	if until, limited := t.limits.Until(rq.URL.Host, now); limited {
+		return nil, &RateLimitError{Host: rq.URL.Host, Until: until}
+	}
+	resp, err := t.base.RoundTrip(rq)
+	if err != nil || !IsRateLimited(resp) {
+		return resp, err
+	}
+	delay, ok := t.policy.serverDelay(resp)
+	if !ok || delay <= 0 {
+		return resp, nil
+	}
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, maxPeekBodySize))
+	_ = resp.Body.Close()
+	until := now.Add(delay)
+	t.limits.Limit(rq.URL.Host, until)
+	return nil, &RateLimitError{Host: rq.URL.Host, Until: until}
+}
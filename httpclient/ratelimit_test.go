@@ -0,0 +1,57 @@
+package httpclient
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/scylladb-actions/get-version/types"
+)
+
+func TestRateLimitTransport(t *testing.T) {
+	var hits atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		if hits.Add(1) == 1 {
+			w.Header().Set("Retry-After", "60")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		_, _ = w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	cl := &http.Client{Transport: rateLimitTransport{
+		base:   http.DefaultTransport,
+		limits: types.NewRateLimits(),
+		policy: RetryPolicy{now: func() time.Time { return now }},
+	}}
+
+	for _, expectedHits := range []int32{1, 1} {
+		_, err := cl.Get(server.URL)
+		var rateLimitErr *RateLimitError
+		if !errors.As(err, &rateLimitErr) || !errors.Is(err, ErrRateLimited) {
+			t.Fatalf("expected rate limit error, got %v", err)
+		}
+		if !rateLimitErr.Until.Equal(now.Add(time.Minute)) {
+			t.Errorf("expected rate limit until %s, got %s", now.Add(time.Minute), rateLimitErr.Until)
+		}
+		if got := hits.Load(); got != expectedHits {
+			t.Errorf("expected %d requests to server, got %d", expectedHits, got)
+		}
+	}
+
+	// The host is queried again once the limit is reset
+	now = now.Add(time.Minute)
+	resp, err := cl.Get(server.URL)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || hits.Load() != 2 {
+		t.Errorf("expected request to reach server after reset, got %s after %d requests", resp.Status, hits.Load())
+	}
+}
@@ -0,0 +1,220 @@
+package httpclient
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/scylladb-actions/get-version/types"
+)
+
+// ErrRateLimited is returned when server reports that rate limit is exceeded
+// and the limit is not going to be reset within the retry budget.
+var ErrRateLimited = errors.New("rate limit exceeded")
+
+// ErrQueryRateLimited is ErrRateLimited reported in the body of a successful response, like GitHub GraphQL
+// RATE_LIMITED error. The http client can't see it, so it is retried by RetryPolicy.Do instead.
+var ErrQueryRateLimited = fmt.Errorf("query %w", ErrRateLimited)
+
+// maxPeekBodySize limits how much of 403 response body is read to tell rate limit from permission error
+const maxPeekBodySize = 64 * 1024
+
+// RetryPolicy describes how failed requests are retried.
+// Rate limited, throttled (429) and failed (5xx) requests and network errors are retried,
+// other 4xx responses are returned to the caller right away.
+type RetryPolicy struct {
+	MaxRetries   int
+	InitialDelay time.Duration
+	MaxDelay     time.Duration
+
+	now func() time.Time
+}
+
+func NewRetryPolicy(p types.Params) RetryPolicy {
+	return RetryPolicy{
+		MaxRetries:   p.RetryMax,
+		InitialDelay: time.Duration(p.RetryInitialDelay) * time.Millisecond,
+		MaxDelay:     time.Duration(p.RetryMaxDelay) * time.Millisecond,
+	}
+}
+
+func (p RetryPolicy) timeNow() time.Time {
+	if p.now != nil {
+		return p.now()
+	}
+	return time.Now()
+}
+
+// backoff returns exponentially growing delay for the attempt, capped at MaxDelay,
+// half of the delay is randomized so that concurrent clients do not retry in lockstep.
+func (p RetryPolicy) backoff(attempt int) time.Duration {
+	delay := p.InitialDelay
+	for range attempt {
+		delay *= 2
+		if delay >= p.MaxDelay {
+			break
+		}
+	}
+	if delay > p.MaxDelay {
+		delay = p.MaxDelay
+	}
+	if delay <= 0 {
+		return 0
+	}
+	half := delay / 2
+	return half + rand.N(delay-half+1)
+}
+
+// serverDelay returns the delay requested by the server through Retry-After
+// or X-RateLimit-Remaining/X-RateLimit-Reset headers.
+func (p RetryPolicy) serverDelay(resp *http.Response) (time.Duration, bool) {
+	if value := resp.Header.Get("Retry-After"); value != "" {
+		if seconds, err := strconv.Atoi(value); err == nil {
+			return time.Duration(seconds) * time.Second, true
+		}
+		if at, err := http.ParseTime(value); err == nil {
+			return max(at.Sub(p.timeNow()), 0), true
+		}
+	}
+	if resp.Header.Get("X-RateLimit-Remaining") == "0" {
+		if reset, err := strconv.ParseInt(resp.Header.Get("X-RateLimit-Reset"), 10, 64); err == nil {
+			return max(time.Unix(reset, 0).Sub(p.timeNow()), 0), true
+		}
+	}
+	return 0, false
+}
+
+// IsRateLimited tells rate limit 403 from permission 403, GitHub reports secondary rate limits
+// only in the response body, so the body is peeked and restored for the caller.
+func IsRateLimited(resp *http.Response) bool {
+	if resp.StatusCode == http.StatusTooManyRequests {
+		return true
+	}
+	if resp.StatusCode != http.StatusForbidden {
+		return false
+	}
+	if resp.Header.Get("Retry-After") != "" || resp.Header.Get("X-RateLimit-Remaining") == "0" {
+		return true
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxPeekBodySize))
+	resp.Body = struct {
+		io.Reader
+		io.Closer
+	}{io.MultiReader(bytes.NewReader(body), resp.Body), resp.Body}
+	return err == nil && strings.Contains(strings.ToLower(string(body)), "rate limit")
+}
+
+func isRetryableStatus(code int) bool {
+	switch code {
+	case http.StatusInternalServerError, http.StatusBadGateway,
+		http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// retryDelay decides whether the attempt should be retried and how long to wait before that.
+func (p RetryPolicy) retryDelay(attempt int, resp *http.Response, err error) (time.Duration, bool) {
+	if attempt >= p.MaxRetries {
+		return 0, false
+	}
+	if err != nil {
+		var certErr *tls.CertificateVerificationError
+		if errors.As(err, &certErr) {
+			// Retrying won't make an untrusted certificate trusted
+			return 0, false
+		}
+		return p.backoff(attempt), true
+	}
+	if IsRateLimited(resp) {
+		delay, ok := p.serverDelay(resp)
+		if !ok {
+			return p.backoff(attempt), true
+		}
+		// There is no point in retrying before the limit is reset
+		return delay, delay <= p.MaxDelay
+	}
+	if isRetryableStatus(resp.StatusCode) {
+		if delay, ok := p.serverDelay(resp); ok && delay <= p.MaxDelay {
+			return delay, true
+		}
+		return p.backoff(attempt), true
+	}
+	return 0, false
+}
+
+func sleep(ctx context.Context, delay time.Duration) error {
+	if delay <= 0 {
+		return ctx.Err()
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// Do calls fn until it succeeds, retrying errors that wrap ErrQueryRateLimited.
+// It is meant for APIs that report rate limits in the response body, like GitHub GraphQL API,
+// everything else, including rate limited responses, is retried by the http client itself.
+func (p RetryPolicy) Do(ctx context.Context, fn func() error) error {
+	for attempt := 0; ; attempt++ {
+		err := fn()
+		if err == nil || !errors.Is(err, ErrQueryRateLimited) || attempt >= p.MaxRetries {
+			return err
+		}
+		if err = sleep(ctx, p.backoff(attempt)); err != nil {
+			return err
+		}
+	}
+}
+
+// retryTransport retries requests according to RetryPolicy.
+type retryTransport struct {
+	base   http.RoundTripper
+	policy RetryPolicy
+}
+
+func (t retryTransport) RoundTrip(rq *http.Request) (*http.Response, error) {
+	for attempt := 0; ; attempt++ {
+		attemptRq := rq
+		if attempt > 0 && rq.Body != nil {
+			if rq.GetBody == nil {
+				return nil, errors.New("failed to retry request: request body can't be rewound")
+			}
+			body, err := rq.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			attemptRq = rq.Clone(rq.Context())
+			attemptRq.Body = body
+		}
+		resp, err := t.base.RoundTrip(attemptRq)
+		if rq.Context().Err() != nil {
+			// Request is canceled or overall deadline is exceeded, as opposed to a timed out attempt
+			return resp, err
+		}
+		delay, retry := t.policy.retryDelay(attempt, resp, err)
+		if !retry {
+			return resp, err
+		}
+		if resp != nil {
+			_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, maxPeekBodySize))
+			_ = resp.Body.Close()
+		}
+		if sleepErr := sleep(rq.Context(), delay); sleepErr != nil {
+			return nil, sleepErr
+		}
+	}
+}
@@ -0,0 +1,239 @@
+package httpclient
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func newTestRetryClient(policy RetryPolicy) *http.Client {
+	return &http.Client{Transport: retryTransport{base: http.DefaultTransport, policy: policy}}
+}
+
+func TestRetryTransport(t *testing.T) {
+	tcases := []struct {
+		name          string
+		respond       func(w http.ResponseWriter, attempt int32)
+		expectedCalls int32
+		expectedCode  int
+	}{
+		{
+			name: "429 is retried",
+			respond: func(w http.ResponseWriter, attempt int32) {
+				if attempt < 3 {
+					w.WriteHeader(http.StatusTooManyRequests)
+				}
+			},
+			expectedCalls: 3,
+			expectedCode:  http.StatusOK,
+		},
+		{
+			name: "5xx is retried",
+			respond: func(w http.ResponseWriter, attempt int32) {
+				if attempt < 2 {
+					w.WriteHeader(http.StatusBadGateway)
+				}
+			},
+			expectedCalls: 2,
+			expectedCode:  http.StatusOK,
+		},
+		{
+			name: "404 is not retried",
+			respond: func(w http.ResponseWriter, _ int32) {
+				w.WriteHeader(http.StatusNotFound)
+			},
+			expectedCalls: 1,
+			expectedCode:  http.StatusNotFound,
+		},
+		{
+			name: "permission 403 is not retried",
+			respond: func(w http.ResponseWriter, _ int32) {
+				w.WriteHeader(http.StatusForbidden)
+				_, _ = w.Write([]byte(`{"message":"Resource not accessible by integration"}`))
+			},
+			expectedCalls: 1,
+			expectedCode:  http.StatusForbidden,
+		},
+		{
+			name: "secondary rate limit 403 is retried",
+			respond: func(w http.ResponseWriter, attempt int32) {
+				if attempt < 2 {
+					w.WriteHeader(http.StatusForbidden)
+					_, _ = w.Write([]byte(`{"message":"You have exceeded a secondary rate limit"}`))
+				}
+			},
+			expectedCalls: 2,
+			expectedCode:  http.StatusOK,
+		},
+		{
+			name: "retries are limited",
+			respond: func(w http.ResponseWriter, _ int32) {
+				w.WriteHeader(http.StatusServiceUnavailable)
+			},
+			expectedCalls: 4,
+			expectedCode:  http.StatusServiceUnavailable,
+		},
+		{
+			name: "rate limit reset beyond max delay is not waited for",
+			respond: func(w http.ResponseWriter, _ int32) {
+				w.Header().Set("X-RateLimit-Remaining", "0")
+				w.Header().Set("X-RateLimit-Reset", strconv.FormatInt(time.Now().Add(time.Hour).Unix(), 10))
+				w.WriteHeader(http.StatusForbidden)
+			},
+			expectedCalls: 1,
+			expectedCode:  http.StatusForbidden,
+		},
+	}
+
+	for _, tcase := range tcases {
+		t.Run(tcase.name, func(t *testing.T) {
+			var calls atomic.Int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				tcase.respond(w, calls.Add(1))
+			}))
+			defer server.Close()
+
+			cl := newTestRetryClient(RetryPolicy{MaxRetries: 3, InitialDelay: time.Millisecond, MaxDelay: 10 * time.Millisecond})
+			resp, err := cl.Get(server.URL)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			body, _ := io.ReadAll(resp.Body)
+			_ = resp.Body.Close()
+			if resp.StatusCode != tcase.expectedCode {
+				t.Errorf("expected status %d, got %d: %s", tcase.expectedCode, resp.StatusCode, body)
+			}
+			if got := calls.Load(); got != tcase.expectedCalls {
+				t.Errorf("expected %d calls, got %d", tcase.expectedCalls, got)
+			}
+		})
+	}
+}
+
+func TestRetryTransportRewindsBody(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if string(body) != "payload" {
+			t.Errorf("expected body %q, got %q", "payload", body)
+		}
+		if calls.Add(1) == 1 {
+			w.WriteHeader(http.StatusTooManyRequests)
+		}
+	}))
+	defer server.Close()
+
+	cl := newTestRetryClient(RetryPolicy{MaxRetries: 3, InitialDelay: time.Millisecond, MaxDelay: time.Millisecond})
+	resp, err := cl.Post(server.URL, "text/plain", bytes.NewReader([]byte("payload")))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || calls.Load() != 2 {
+		t.Fatalf("expected success on second call, got %s after %d calls", resp.Status, calls.Load())
+	}
+}
+
+func TestRetryDelay(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	policy := RetryPolicy{
+		MaxRetries:   5,
+		InitialDelay: time.Second,
+		MaxDelay:     time.Minute,
+		now:          func() time.Time { return now },
+	}
+
+	tcases := []struct {
+		name     string
+		header   http.Header
+		expected time.Duration
+	}{
+		{
+			name:     "Retry-After seconds",
+			header:   http.Header{"Retry-After": []string{"7"}},
+			expected: 7 * time.Second,
+		},
+		{
+			name:     "Retry-After date",
+			header:   http.Header{"Retry-After": []string{now.Add(20 * time.Second).Format(http.TimeFormat)}},
+			expected: 20 * time.Second,
+		},
+		{
+			name: "X-RateLimit-Reset",
+			header: http.Header{
+				"X-Ratelimit-Remaining": []string{"0"},
+				"X-Ratelimit-Reset":     []string{fmt.Sprint(now.Add(30 * time.Second).Unix())},
+			},
+			expected: 30 * time.Second,
+		},
+	}
+
+	for _, tcase := range tcases {
+		t.Run(tcase.name, func(t *testing.T) {
+			resp := &http.Response{
+				StatusCode: http.StatusTooManyRequests,
+				Header:     tcase.header,
+				Body:       http.NoBody,
+			}
+			delay, retry := policy.retryDelay(0, resp, nil)
+			if !retry {
+				t.Fatalf("expected request to be retried")
+			}
+			if delay != tcase.expected {
+				t.Fatalf("expected delay %s, got %s", tcase.expected, delay)
+			}
+		})
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	policy := RetryPolicy{InitialDelay: time.Second, MaxDelay: 10 * time.Second}
+	expectedDelays := []time.Duration{
+		time.Second, 2 * time.Second, 4 * time.Second, 8 * time.Second, 10 * time.Second, 10 * time.Second,
+	}
+	for attempt, expected := range expectedDelays {
+		delay := policy.backoff(attempt)
+		if delay < expected/2 || delay > expected {
+			t.Errorf("attempt %d: expected delay within [%s, %s], got %s", attempt, expected/2, expected, delay)
+		}
+	}
+}
+
+func TestRetryPolicyDo(t *testing.T) {
+	policy := RetryPolicy{MaxRetries: 2, InitialDelay: time.Millisecond, MaxDelay: time.Millisecond}
+	calls := 0
+	err := policy.Do(context.Background(), func() error {
+		calls++
+		return fmt.Errorf("%w: try later", ErrQueryRateLimited)
+	})
+	if !errors.Is(err, ErrRateLimited) || calls != 3 {
+		t.Fatalf("expected rate limit error after 3 calls, got %v after %d calls", err, calls)
+	}
+
+	// Rate limited responses are already retried by the http client
+	calls = 0
+	err = policy.Do(context.Background(), func() error {
+		calls++
+		return fmt.Errorf("%w: server replied with 429", ErrRateLimited)
+	})
+	if err == nil || calls != 1 {
+		t.Fatalf("expected rate limited responses not to be retried, got %v after %d calls", err, calls)
+	}
+
+	calls = 0
+	err = policy.Do(context.Background(), func() error {
+		calls++
+		return fmt.Errorf("not found")
+	})
+	if err == nil || calls != 1 {
+		t.Fatalf("expected other errors not to be retried, got %v after %d calls", err, calls)
+	}
+}
@@ -0,0 +1,83 @@
+package httpclient
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/scylladb-actions/get-version/types"
+)
+
+// NewTLSConfig builds TLS configuration from params.
+// Server certificates are verified against system CAs, which respect SSL_CERT_FILE and SSL_CERT_DIR,
+// plus CAs from --ca-bundle.
+func NewTLSConfig(p types.Params) (*tls.Config, error) {
+	cfg := &tls.Config{MinVersion: tls.VersionTLS12}
+	if p.Insecure {
+		_, _ = fmt.Fprintln(p.LogOutput(),
+			"WARNING: TLS certificate verification is disabled by --insecure, "+
+				"credentials can be intercepted by anyone on the network path")
+		cfg.InsecureSkipVerify = true
+	}
+	if p.CABundle != "" {
+		pool, err := loadCABundle(nil, p.CABundle)
+		if err != nil {
+			return nil, err
+		}
+		cfg.RootCAs = pool
+	}
+	if p.ClientCert != "" || p.ClientKey != "" {
+		cert, err := tls.LoadX509KeyPair(p.ClientCert, p.ClientKey)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load TLS client certificate: %w", err)
+		}
+		cfg.Certificates = []tls.Certificate{cert}
+	}
+	return cfg, nil
+}
+
+// loadCABundle returns base CA pool, or system one when it is nil, extended with certificates from a PEM file
+// or from all files of a directory. Base pool is not modified.
+func loadCABundle(base *x509.CertPool, path string) (*x509.CertPool, error) {
+	var pool *x509.CertPool
+	if base != nil {
+		pool = base.Clone()
+	} else if systemPool, err := x509.SystemCertPool(); err == nil {
+		pool = systemPool
+	} else {
+		pool = x509.NewCertPool()
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CA bundle: %w", err)
+	}
+	files := []string{path}
+	if info.IsDir() {
+		entries, err := os.ReadDir(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CA bundle directory: %w", err)
+		}
+		files = files[:0]
+		for _, entry := range entries {
+			if entry.Type().IsRegular() {
+				files = append(files, filepath.Join(path, entry.Name()))
+			}
+		}
+	}
+	added := false
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CA bundle: %w", err)
+		}
+		if pool.AppendCertsFromPEM(data) {
+			added = true
+		}
+	}
+	if !added {
+		return nil, fmt.Errorf("no PEM certificates found in CA bundle %q", path)
+	}
+	return pool, nil
+}
@@ -0,0 +1,194 @@
+package httpclient
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/scylladb-actions/get-version/types"
+)
+
+func writePEM(t *testing.T, path, blockType string, data []byte) {
+	t.Helper()
+	if err := os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: data}), 0o600); err != nil {
+		t.Fatalf("failed to write %s: %v", path, err)
+	}
+}
+
+// newClientCert issues a client certificate signed by a fresh CA and returns the CA pool
+// together with certificate and key files.
+func newClientCert(t *testing.T, dir string) (caPool *x509.CertPool, certFile, keyFile string) {
+	t.Helper()
+	caKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	caTemplate := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test-ca"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}
+	caDER, err := x509.CreateCertificate(rand.Reader, caTemplate, caTemplate, &caKey.PublicKey, caKey)
+	if err != nil {
+		t.Fatalf("failed to create CA certificate: %v", err)
+	}
+	caCert, err := x509.ParseCertificate(caDER)
+	if err != nil {
+		t.Fatalf("failed to parse CA certificate: %v", err)
+	}
+
+	clientKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	clientTemplate := &x509.Certificate{
+		SerialNumber: big.NewInt(2),
+		Subject:      pkix.Name{CommonName: "test-client"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	clientDER, err := x509.CreateCertificate(rand.Reader, clientTemplate, caCert, &clientKey.PublicKey, caKey)
+	if err != nil {
+		t.Fatalf("failed to create client certificate: %v", err)
+	}
+	keyDER, err := x509.MarshalPKCS8PrivateKey(clientKey)
+	if err != nil {
+		t.Fatalf("failed to marshal key: %v", err)
+	}
+
+	certFile = filepath.Join(dir, "client.crt")
+	keyFile = filepath.Join(dir, "client.key")
+	writePEM(t, certFile, "CERTIFICATE", clientDER)
+	writePEM(t, keyFile, "PRIVATE KEY", keyDER)
+	caPool = x509.NewCertPool()
+	caPool.AddCert(caCert)
+	return caPool, certFile, keyFile
+}
+
+func TestTLSVerification(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	bundleDir := filepath.Join(dir, "bundle")
+	if err := os.Mkdir(bundleDir, 0o700); err != nil {
+		t.Fatalf("failed to create directory: %v", err)
+	}
+	bundleFile := filepath.Join(bundleDir, "server.pem")
+	writePEM(t, bundleFile, "CERTIFICATE", server.Certificate().Raw)
+
+	tcases := []struct {
+		name      string
+		params    types.Params
+		expectErr bool
+	}{
+		{
+			name:      "verified by default",
+			params:    types.Params{},
+			expectErr: true,
+		},
+		{
+			name:   "insecure",
+			params: types.Params{Insecure: true},
+		},
+		{
+			name:   "ca bundle file",
+			params: types.Params{CABundle: bundleFile},
+		},
+		{
+			name:   "ca bundle directory",
+			params: types.Params{CABundle: bundleDir},
+		},
+	}
+
+	for _, tcase := range tcases {
+		t.Run(tcase.name, func(t *testing.T) {
+			cl, err := New(tcase.params)
+			if err != nil {
+				t.Fatalf("failed to create client: %v", err)
+			}
+			resp, err := cl.Get(server.URL)
+			if tcase.expectErr {
+				if err == nil {
+					_ = resp.Body.Close()
+					t.Fatalf("expected certificate verification error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			_ = resp.Body.Close()
+		})
+	}
+}
+
+func TestTLSClientCertificate(t *testing.T) {
+	dir := t.TempDir()
+	clientCAs, certFile, keyFile := newClientCert(t, dir)
+
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}))
+	server.TLS = &tls.Config{ClientAuth: tls.RequireAndVerifyClientCert, ClientCAs: clientCAs}
+	server.StartTLS()
+	defer server.Close()
+
+	cl, err := New(types.Params{Insecure: true, ClientCert: certFile, ClientKey: keyFile})
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+	resp, err := cl.Get(server.URL)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	_ = resp.Body.Close()
+}
+
+func TestLoadCABundleWithoutCertificates(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "empty.pem")
+	if err := os.WriteFile(file, []byte("not a certificate"), 0o600); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+	if _, err := loadCABundle(nil, file); err == nil {
+		t.Fatalf("expected error for bundle without certificates")
+	}
+}
+
+func TestHostCABundleKeepsGlobalOne(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	globalBundle := filepath.Join(dir, "server.pem")
+	writePEM(t, globalBundle, "CERTIFICATE", server.Certificate().Raw)
+	_, hostBundle, _ := newClientCert(t, dir)
+
+	cl, err := New(types.Params{CABundle: globalBundle, NetworkConfig: writeNetworkConfig(t, `
+hosts:
+  127.0.0.1:
+    ca-bundle: `+hostBundle+`
+`)})
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+	resp, err := cl.Get(server.URL)
+	if err != nil {
+		t.Fatalf("expected --ca-bundle to be trusted for a host with its own CA bundle: %v", err)
+	}
+	_ = resp.Body.Close()
+}
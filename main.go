@@ -1,58 +1,256 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"fmt"
 	"os"
+	"os/signal"
+	"strings"
+	"sync"
+	"syscall"
 
-	"github.com/scylladb-actions/get-version/filters"
+	"github.com/scylladb-actions/get-version/bump"
+	"github.com/scylladb-actions/get-version/check"
+	"github.com/scylladb-actions/get-version/ghaction"
 	"github.com/scylladb-actions/get-version/output"
 	"github.com/scylladb-actions/get-version/sources"
 	"github.com/scylladb-actions/get-version/types"
+	"github.com/scylladb-actions/get-version/version"
 )
 
+// Exit codes tell apart why the tool failed, 2 is also used by flag package for unknown flags.
+// Update exit codes are used in check mode with --exit-code.
+const (
+	exitSourceError = 1
+	exitParseError  = 2
+	exitNoMatch     = 3
+	exitPatchUpdate = 4
+	exitMinorUpdate = 5
+	exitMajorUpdate = 6
+)
+
+var updateExitCodes = map[check.Kind]int{
+	check.KindPatch: exitPatchUpdate,
+	check.KindMinor: exitMinorUpdate,
+	check.KindMajor: exitMajorUpdate,
+}
+
 func main() {
 	p := types.Params{}
 	err := p.Parse(sources.AllSources)
 	if err != nil {
 		fmt.Fprintln(os.Stderr, err.Error())
 		flag.Usage()
-		os.Exit(1)
+		os.Exit(exitParseError)
 	}
 	if p.ShowVersion {
 		fmt.Fprintln(os.Stdout, buildVersion)
 		return
 	}
+	if exitCode, done := runCommand(p); done {
+		os.Exit(exitCode)
+	}
 
-	source, err := sources.AllSources.GetSource(p)
-	if err != nil {
-		fmt.Fprintln(os.Stderr, err.Error())
-		os.Exit(1)
+	queries := []types.Query{{Params: p}}
+	if p.Config != "" {
+		queries, err = types.LoadConfig(p.Config, os.Args[1:], sources.AllSources)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err.Error())
+			os.Exit(exitParseError)
+		}
 	}
 
-	filter, err := filters.ParseFilterString(p.FiltersDefinition)
-	if err != nil {
-		fmt.Fprintln(os.Stderr, err.Error())
-		os.Exit(1)
+	runs := make([]*queryRun, 0, len(queries))
+	byName := map[string]*queryRun{}
+	for _, query := range queries {
+		run, err := newQueryRun(query)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err.Error())
+			os.Exit(exitParseError)
+		}
+		for _, name := range query.Params.CombineOf {
+			run.inputs = append(run.inputs, byName[name])
+		}
+		byName[query.Name] = run
+		runs = append(runs, run)
 	}
 
-	o, err := output.NewOutput(p)
-	if err != nil {
-		fmt.Fprintln(os.Stderr, err.Error())
-		os.Exit(1)
+	var wg sync.WaitGroup
+	for _, run := range runs {
+		if run.source == nil {
+			continue
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			run.fetch()
+		}()
+	}
+	wg.Wait()
+	// Sets go after queries and can combine sets listed before them
+	for _, run := range runs {
+		if run.source == nil {
+			run.combine()
+		}
+	}
+
+	// Results are written in order of queries, the first failure sets exit code
+	exitCode := 0
+	for _, run := range runs {
+		if code := run.finish(); exitCode == 0 {
+			exitCode = code
+		}
+	}
+	if exitCode != 0 {
+		os.Exit(exitCode)
+	}
+}
+
+// newWriter returns a function that writes selected versions and returns exit code for them,
+// in check mode it compares them against the current version and with --update writes the newest one to the file,
+// with --bump it computes the next release version. When writing fails with exit code 0, exitSourceError is used.
+func newWriter(p types.Params) (func(version.Versions) (int, error), error) {
+	if p.NextMode() {
+		return newNextWriter(p)
+	}
+	if !p.CheckMode() {
+		o, err := output.NewOutput(p)
+		if err != nil {
+			return nil, err
+		}
+		return func(versions version.Versions) (int, error) {
+			return 0, o.Write(versions)
+		}, nil
 	}
 
-	allVersions, _, err := source.GetAllVersions()
+	current, currentPin, err := check.Current(p)
 	if err != nil {
-		fmt.Fprintln(os.Stderr, err.Error())
-		os.Exit(1)
+		return nil, err
+	}
+	o, err := output.NewCheck(p)
+	if err != nil {
+		return nil, err
 	}
+	return func(versions version.Versions) (int, error) {
+		result := check.Compare(current, versions)
+		result.Pin = currentPin
+		if p.Update {
+			if err := check.Update(result, p.DryRun, os.Stdout); err != nil {
+				return 0, err
+			}
+		}
+		// Diff of --dry-run takes stdout, so that it can be applied as a patch
+		if !p.DryRun || p.OutAsAction {
+			if err := o.Write(result); err != nil {
+				return 0, err
+			}
+		}
+		if !p.ExitCode {
+			return 0, nil
+		}
+		return updateExitCodes[result.Kind], nil
+	}, nil
+}
 
-	filteredVersions := filter.Apply(allVersions)
+// reportToAction writes job summary and annotations, failing to write them does not fail the tool
+func reportToAction(
+	p types.Params,
+	all version.Versions,
+	ignored []types.IgnoredVersion,
+	selected version.Versions,
+	countErr error,
+) {
+	source, query := p.SourceName, p.Repo
+	switch {
+	case p.SourceName == types.MavenArtifact:
+		query = p.MavenGroup + ":" + p.MavenArtifactID
+	case p.SourceName == types.File:
+		query = p.File
+	case p.Combine != "":
+		source, query = types.SourceName(p.Combine), strings.Join(p.CombineOf, ", ")
+	}
+	summary := ghaction.Summary{
+		Source:   source,
+		Query:    query,
+		Filter:   p.FiltersDefinition,
+		Found:    len(all),
+		Ignored:  ignored,
+		Selected: selected,
+		NoPrefix: p.OutNoPrefix,
+	}
+	if err := ghaction.WriteSummary(summary.Markdown()); err != nil {
+		fmt.Fprintln(os.Stderr, "failed to write job summary:", err.Error())
+	}
+
+	if len(ignored) != 0 {
+		var names []string
+		for _, ignoredVersion := range ignored[:min(len(ignored), 10)] {
+			names = append(names, ignoredVersion.Version)
+		}
+		if len(ignored) > len(names) {
+			names = append(names, fmt.Sprintf("and %d more", len(ignored)-len(names)))
+		}
+		ghaction.Warning(fmt.Sprintf("%s %s: %d versions were ignored, see job summary for reasons: %s",
+			source, query, len(ignored), strings.Join(names, ", ")))
+	}
+	switch {
+	case countErr != nil:
+		ghaction.Error(fmt.Sprintf("%s %s: %s, filter %q, %d versions were found",
+			source, query, countErr, p.FiltersDefinition, len(all)))
+	case len(selected) == 0:
+		ghaction.Error(fmt.Sprintf("%s %s: no versions match filter %q, %d versions were found",
+			source, query, p.FiltersDefinition, len(all)))
+	}
+}
 
-	err = o.Write(filteredVersions)
+// newNextWriter returns a function that writes the next release version computed from selected versions
+func newNextWriter(p types.Params) (func(version.Versions) (int, error), error) {
+	var branch *bump.Branch
+	if p.Branch != "" {
+		parsed, err := bump.ParseBranch(p.Branch)
+		if err != nil {
+			return nil, err
+		}
+		branch = &parsed
+	}
+	o, err := output.NewNext(p)
 	if err != nil {
-		fmt.Fprintln(os.Stderr, err.Error())
-		os.Exit(1)
+		return nil, err
+	}
+	return func(versions version.Versions) (int, error) {
+		result, err := bump.Next(versions, p.Bump, branch, p.Prefix)
+		if err != nil {
+			return exitNoMatch, err
+		}
+		return 0, o.Write(result)
+	}, nil
+}
+
+// newContext returns a context that is canceled on SIGINT or SIGTERM and when --timeout expires,
+// context.Cause tells which one happened.
+func newContext(p types.Params) (context.Context, context.CancelFunc) {
+	ctx, cancelCause := context.WithCancelCause(context.Background())
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		select {
+		case sig := <-signals:
+			cancelCause(fmt.Errorf("interrupted by %s signal", sig))
+		case <-ctx.Done():
+		}
+	}()
+	cancel := func() {
+		signal.Stop(signals)
+		cancelCause(context.Canceled)
+	}
+	if p.Timeout <= 0 {
+		return ctx, cancel
+	}
+	ctx, cancelTimeout := context.WithTimeoutCause(ctx, p.Timeout,
+		fmt.Errorf("timed out after %s, see --timeout", p.Timeout))
+	return ctx, func() {
+		cancelTimeout()
+		cancel()
 	}
 }
@@ -0,0 +1,141 @@
+package output
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"slices"
+	"strconv"
+
+	"github.com/scylladb-actions/get-version/types"
+	"github.com/scylladb-actions/get-version/version"
+)
+
+// NewAction creates an output writer that publishes versions as GitHub Action outputs to $GITHUB_OUTPUT.
+func NewAction(params types.Params) (Action, error) {
+	path := os.Getenv("GITHUB_OUTPUT")
+	if path == "" {
+		return Action{}, fmt.Errorf("GITHUB_OUTPUT is not set")
+	}
+	// Catch errors in format options, like broken template, before anything is fetched
+	if _, err := NewFormat(params, &bytes.Buffer{}); err != nil {
+		return Action{}, err
+	}
+	return Action{params: params, path: path}, nil
+}
+
+// Action writes versions in --out-format to --out-name output, together with extra outputs:
+// latest, first, count, major, minor and patch of the latest version and versions-text with a version per line.
+// Extra outputs are prefixed with "<name>-" unless the name is "versions", versions-text is "<name>-text".
+type Action struct {
+	params types.Params
+	path   string
+}
+
+func (o Action) outputName(name string) string {
+	if o.params.OutName == types.DefaultOutName {
+		return name
+	}
+	return o.params.OutName + "-" + name
+}
+
+func (o Action) Write(versions version.Versions) error {
+	var formatted bytes.Buffer
+	format, err := NewFormat(o.params, &formatted)
+	if err != nil {
+		return err
+	}
+	if err = format.Write(versions); err != nil {
+		return err
+	}
+	var text bytes.Buffer
+	if err = NewText(o.params, &text).Write(versions); err != nil {
+		return err
+	}
+
+	outputs := []actionOutput{
+		{name: o.params.OutName, value: formatted.String()},
+		{name: o.params.OutName + "-text", value: text.String()},
+		{name: o.outputName("count"), value: strconv.Itoa(len(versions))},
+	}
+	// Release candidates of the same version are told apart by number, the same way latest command does
+	if latest, ok := versions.Newest(); ok {
+		first := slices.MinFunc(versions, version.Version.CmpRC)
+		outputs = append(outputs, []actionOutput{
+			{name: o.outputName("latest"), value: versionString(o.params, latest)},
+			{name: o.outputName("first"), value: versionString(o.params, first)},
+			{name: o.outputName("major"), value: latest.MajorStr()},
+			{name: o.outputName("minor"), value: latest.MinorStr()},
+			{name: o.outputName("patch"), value: latest.PatchStr()},
+		}...)
+	}
+
+	return o.writeOutputs(outputs)
+}
+
+type actionOutput struct {
+	name  string
+	value string
+}
+
+// writeOutputs appends outputs to $GITHUB_OUTPUT at once
+func (o Action) writeOutputs(outputs []actionOutput) error {
+	var buf bytes.Buffer
+	for _, output := range outputs {
+		if err := writeActionOutput(&buf, output.name, output.value); err != nil {
+			return err
+		}
+	}
+	return appendFile(o.path, buf.Bytes())
+}
+
+// appendFile appends data to file at path, the way runner expects files like $GITHUB_OUTPUT to be written
+func appendFile(path string, data []byte) error {
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to open file %q: %w", path, err)
+	}
+	defer func() {
+		_ = file.Close()
+	}()
+	if _, err = file.Write(data); err != nil {
+		return fmt.Errorf("failed to write to file %q: %w", path, err)
+	}
+	return nil
+}
+
+func versionString(params types.Params, v version.Version) string {
+	if params.OutNoPrefix {
+		return v.NoPrefixString()
+	}
+	return v.String()
+}
+
+// writeActionOutput writes output in name<<DELIMITER syntax, which allows multi-line values.
+// The delimiter is random, so that values can't end the output early and inject other outputs.
+func writeActionOutput(buf *bytes.Buffer, name, value string) error {
+	delimiter, err := newActionDelimiter(value)
+	if err != nil {
+		return err
+	}
+	if value != "" && value[len(value)-1] != '\n' {
+		value += "\n"
+	}
+	_, _ = fmt.Fprintf(buf, "%s<<%s\n%s%s\n", name, delimiter, value, delimiter)
+	return nil
+}
+
+func newActionDelimiter(value string) (string, error) {
+	for {
+		random := make([]byte, 16)
+		if _, err := rand.Read(random); err != nil {
+			return "", fmt.Errorf("failed to generate output delimiter: %w", err)
+		}
+		delimiter := "ghadelimiter_" + hex.EncodeToString(random)
+		if !bytes.Contains([]byte(value), []byte(delimiter)) {
+			return delimiter, nil
+		}
+	}
+}
@@ -0,0 +1,147 @@
+package output
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/scylladb-actions/get-version/types"
+	"github.com/scylladb-actions/get-version/version"
+)
+
+// parseActionOutputs parses $GITHUB_OUTPUT file the way GitHub runner does
+func parseActionOutputs(t *testing.T, path string) map[string]string {
+	t.Helper()
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("failed to open outputs: %v", err)
+	}
+	defer func() {
+		_ = file.Close()
+	}()
+	outputs := map[string]string{}
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if name, value, ok := strings.Cut(line, "="); ok && !strings.Contains(name, "<<") {
+			outputs[name] = value
+			continue
+		}
+		name, delimiter, ok := strings.Cut(line, "<<")
+		if !ok {
+			t.Fatalf("unexpected line %q", line)
+		}
+		var lines []string
+		for scanner.Scan() && scanner.Text() != delimiter {
+			lines = append(lines, scanner.Text())
+		}
+		outputs[name] = strings.Join(lines, "\n")
+	}
+	return outputs
+}
+
+func TestAction(t *testing.T) {
+	versions := version.Versions{
+		version.NewMust("6.1.5"),
+		version.NewMust("6.2.1"),
+		version.NewMust("6.2.0-rc1"),
+	}
+
+	tcases := []struct {
+		name     string
+		params   types.Params
+		versions version.Versions
+		expected map[string]string
+	}{
+		{
+			name:     "yaml",
+			params:   types.Params{OutName: types.DefaultOutName, OutFormat: types.OutputYAML},
+			versions: versions,
+			expected: map[string]string{
+				"versions":      "- 6.1.5\n- 6.2.0-rc1\n- 6.2.1",
+				"versions-text": "6.1.5\n6.2.0-rc1\n6.2.1",
+				"count":         "3",
+				"latest":        "6.2.1",
+				"first":         "6.1.5",
+				"major":         "6",
+				"minor":         "2",
+				"patch":         "1",
+			},
+		},
+		{
+			name:     "custom name",
+			params:   types.Params{OutName: "scylla", OutFormat: types.OutputJSON, OutReverseOrder: true},
+			versions: versions,
+			expected: map[string]string{
+				"scylla":        `["6.2.1","6.2.0-rc1","6.1.5"]`,
+				"scylla-text":   "6.2.1\n6.2.0-rc1\n6.1.5",
+				"scylla-count":  "3",
+				"scylla-latest": "6.2.1",
+				"scylla-first":  "6.1.5",
+				"scylla-major":  "6",
+				"scylla-minor":  "2",
+				"scylla-patch":  "1",
+			},
+		},
+		{
+			name:   "release candidates",
+			params: types.Params{OutName: types.DefaultOutName, OutFormat: types.OutputTEXT},
+			versions: version.Versions{
+				version.NewMust("6.2.0-rc2"),
+				version.NewMust("6.2.0-rc10"),
+				version.NewMust("6.2.0-rc1"),
+			},
+			expected: map[string]string{
+				"versions":      "6.2.0-rc2\n6.2.0-rc10\n6.2.0-rc1",
+				"versions-text": "6.2.0-rc2\n6.2.0-rc10\n6.2.0-rc1",
+				"count":         "3",
+				"latest":        "6.2.0-rc10",
+				"first":         "6.2.0-rc1",
+				"major":         "6",
+				"minor":         "2",
+				"patch":         "0-rc10",
+			},
+		},
+		{
+			name:   "no versions",
+			params: types.Params{OutName: types.DefaultOutName, OutFormat: types.OutputJSON},
+			expected: map[string]string{
+				"versions":      "[]",
+				"versions-text": "",
+				"count":         "0",
+			},
+		},
+	}
+
+	for _, tcase := range tcases {
+		t.Run(tcase.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "output")
+			if err := os.WriteFile(path, []byte("previous=value\n"), 0o600); err != nil {
+				t.Fatalf("failed to create outputs: %v", err)
+			}
+			t.Setenv("GITHUB_OUTPUT", path)
+			out, err := NewAction(tcase.params)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if err = out.Write(tcase.versions); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			outputs := parseActionOutputs(t, path)
+			if outputs["previous"] != "value" {
+				t.Fatalf("expected existing outputs to be kept, got %v", outputs)
+			}
+			delete(outputs, "previous")
+			if len(outputs) != len(tcase.expected) {
+				t.Fatalf("expected outputs %v, got %v", tcase.expected, outputs)
+			}
+			for name, value := range tcase.expected {
+				if outputs[name] != value {
+					t.Errorf("expected output %s=%q, got %q", name, value, outputs[name])
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,108 @@
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/scylladb-actions/get-version/check"
+	"github.com/scylladb-actions/get-version/types"
+)
+
+// NewCheck creates a writer of check mode result, it writes to GitHub Action outputs with --out-as-action
+// and to stdout otherwise.
+func NewCheck(params types.Params) (Check, error) {
+	if !params.OutAsAction {
+		return Check{params: params, output: os.Stdout}, nil
+	}
+	action, err := NewAction(params)
+	if err != nil {
+		return Check{}, err
+	}
+	return Check{params: params, action: &action}, nil
+}
+
+// Check writes result of comparing the current version against the source.
+// As GitHub Action outputs it is update-available, update-kind, current-version, new-version and latest,
+// prefixed with "<name>-" unless --out-name is "versions".
+type Check struct {
+	params types.Params
+	output io.Writer
+	action *Action
+}
+
+// checkReport is check result as it is written in json and yaml
+type checkReport struct {
+	UpdateAvailable bool       `json:"update-available" yaml:"update-available"`
+	UpdateKind      check.Kind `json:"update-kind" yaml:"update-kind"`
+	Current         string     `json:"current-version" yaml:"current-version"`
+	New             string     `json:"new-version,omitempty" yaml:"new-version,omitempty"`
+	Latest          string     `json:"latest,omitempty" yaml:"latest,omitempty"`
+	File            string     `json:"file,omitempty" yaml:"file,omitempty"`
+	Line            int        `json:"line,omitempty" yaml:"line,omitempty"`
+}
+
+func (o Check) report(result check.Result) checkReport {
+	report := checkReport{
+		UpdateAvailable: result.UpdateAvailable(),
+		UpdateKind:      result.Kind,
+		Current:         result.Current.String(),
+	}
+	if result.Pin != nil {
+		report.Current = result.Pin.Value
+		report.File, report.Line = result.Pin.Path, result.Pin.Line
+	}
+	if result.Latest != nil {
+		report.Latest = versionString(o.params, *result.Latest)
+	}
+	if result.UpdateAvailable() {
+		report.New = report.Latest
+	}
+	return report
+}
+
+func (o Check) Write(result check.Result) error {
+	report := o.report(result)
+	if o.action != nil {
+		return o.writeAction(report)
+	}
+	switch o.params.OutFormat {
+	case types.OutputJSON:
+		return json.NewEncoder(o.output).Encode(report)
+	case types.OutputYAML:
+		return yaml.NewEncoder(o.output).Encode(report)
+	default:
+		return o.writeText(report)
+	}
+}
+
+func (o Check) writeText(report checkReport) error {
+	var location string
+	if report.File != "" {
+		location = fmt.Sprintf("%s:%d: ", report.File, report.Line)
+	}
+	var err error
+	switch {
+	case report.UpdateAvailable:
+		_, err = fmt.Fprintf(o.output, "%s%s -> %s, %s update\n", location, report.Current, report.New, report.UpdateKind)
+	case report.Latest == "":
+		_, err = fmt.Fprintf(o.output, "%s%s, no versions match filters\n", location, report.Current)
+	default:
+		_, err = fmt.Fprintf(o.output, "%s%s is up to date, latest is %s\n", location, report.Current, report.Latest)
+	}
+	return err
+}
+
+func (o Check) writeAction(report checkReport) error {
+	return o.action.writeOutputs([]actionOutput{
+		{name: o.action.outputName("update-available"), value: strconv.FormatBool(report.UpdateAvailable)},
+		{name: o.action.outputName("update-kind"), value: string(report.UpdateKind)},
+		{name: o.action.outputName("current-version"), value: report.Current},
+		{name: o.action.outputName("new-version"), value: report.New},
+		{name: o.action.outputName("latest"), value: report.Latest},
+	})
+}
@@ -0,0 +1,99 @@
+package output
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/scylladb-actions/get-version/check"
+	"github.com/scylladb-actions/get-version/pin"
+	"github.com/scylladb-actions/get-version/types"
+	"github.com/scylladb-actions/get-version/version"
+)
+
+func TestCheck(t *testing.T) {
+	update := check.Compare(version.NewMust("6.1.3"), version.Versions{version.NewMust("6.2.1")})
+	update.Pin = &pin.Pin{Path: "Dockerfile", Line: 3, Value: "6.1.3"}
+	upToDate := check.Compare(version.NewMust("6.2.1"), version.Versions{version.NewMust("6.2.1")})
+
+	tcases := []struct {
+		name     string
+		params   types.Params
+		result   check.Result
+		expected string
+	}{
+		{
+			name:     "text update",
+			params:   types.Params{},
+			result:   update,
+			expected: "Dockerfile:3: 6.1.3 -> 6.2.1, minor update\n",
+		},
+		{
+			name:     "text up to date",
+			params:   types.Params{},
+			result:   upToDate,
+			expected: "6.2.1 is up to date, latest is 6.2.1\n",
+		},
+		{
+			name:   "json",
+			params: types.Params{OutFormat: types.OutputJSON},
+			result: update,
+			expected: `{"update-available":true,"update-kind":"minor","current-version":"6.1.3",` +
+				`"new-version":"6.2.1","latest":"6.2.1","file":"Dockerfile","line":3}` + "\n",
+		},
+		{
+			name:     "yaml",
+			params:   types.Params{OutFormat: types.OutputYAML},
+			result:   upToDate,
+			expected: "update-available: false\nupdate-kind: none\ncurrent-version: 6.2.1\nlatest: 6.2.1\n",
+		},
+	}
+
+	for _, tcase := range tcases {
+		t.Run(tcase.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			o := Check{params: tcase.params, output: &buf}
+			if err := o.Write(tcase.result); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if buf.String() != tcase.expected {
+				t.Errorf("expected %q, got %q", tcase.expected, buf.String())
+			}
+		})
+	}
+}
+
+func TestCheckAction(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "output")
+	if err := os.WriteFile(path, nil, 0o644); err != nil {
+		t.Fatalf("failed to create outputs file: %v", err)
+	}
+	t.Setenv("GITHUB_OUTPUT", path)
+
+	o, err := NewCheck(types.Params{OutAsAction: true, OutName: "scylla", OutFormat: types.OutputJSON})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	result := check.Compare(version.NewMust("6.1.3"), version.Versions{version.NewMust("2025.1.0")})
+	if err = o.Write(result); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := map[string]string{
+		"scylla-update-available": "true",
+		"scylla-update-kind":      "major",
+		"scylla-current-version":  "6.1.3",
+		"scylla-new-version":      "2025.1.0",
+		"scylla-latest":           "2025.1.0",
+	}
+	outputs := parseActionOutputs(t, path)
+	if len(outputs) != len(expected) {
+		t.Errorf("expected %d outputs, got %v", len(expected), outputs)
+	}
+	for name, value := range expected {
+		if outputs[name] != value {
+			t.Errorf("expected output %s=%q, got %q", name, value, outputs[name])
+		}
+	}
+}
@@ -0,0 +1,143 @@
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/scylladb-actions/get-version/types"
+	"github.com/scylladb-actions/get-version/version"
+)
+
+const matrixMetadataPrefix = "metadata."
+
+// matrixFields are fields of a version that can be used as matrix dimensions
+var matrixFields = map[string]func(v version.Version, withPrefix bool) string{
+	"version": func(v version.Version, withPrefix bool) string {
+		if withPrefix {
+			return v.String()
+		}
+		return v.NoPrefixString()
+	},
+	"major": func(v version.Version, _ bool) string {
+		return v.MajorStr()
+	},
+	"minor": func(v version.Version, _ bool) string {
+		return v.MinorStr()
+	},
+	// patch includes extra, like 0-rc1, the same as patch output of the action
+	"patch": func(v version.Version, _ bool) string {
+		return v.PatchStr()
+	},
+	"patch-number": func(v version.Version, _ bool) string {
+		return strconv.Itoa(v.Patch())
+	},
+	"extra": func(v version.Version, _ bool) string {
+		return v.Extra()
+	},
+	"prefix": func(v version.Version, _ bool) string {
+		return v.Prefix()
+	},
+}
+
+type matrixKey struct {
+	name string
+	get  func(v version.Version, withPrefix bool) string
+}
+
+// parseMatrixKeys parses --out-matrix-keys, a comma separated list of field or field=key
+func parseMatrixKeys(value string) ([]matrixKey, error) {
+	var keys []matrixKey
+	seen := map[string]bool{}
+	for _, chunk := range strings.Split(value, ",") {
+		field, name, renamed := strings.Cut(strings.TrimSpace(chunk), "=")
+		if !renamed {
+			name = field
+		}
+		if field == "" || name == "" {
+			return nil, fmt.Errorf("invalid matrix key %q, should be field or field=key", chunk)
+		}
+		key := matrixKey{name: name, get: matrixFields[field]}
+		if metadataKey, ok := strings.CutPrefix(field, matrixMetadataPrefix); ok && metadataKey != "" {
+			key.get = func(v version.Version, _ bool) string {
+				return v.Metadata()[metadataKey]
+			}
+		}
+		if key.get == nil {
+			return nil, fmt.Errorf("unknown matrix field %q", field)
+		}
+		if seen[name] {
+			return nil, fmt.Errorf("duplicate matrix key %q", name)
+		}
+		seen[name] = true
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
+
+// parseMatrixExtra parses --out-matrix-extra values, comma separated lists of key=value
+func parseMatrixExtra(values []string) (map[string]string, error) {
+	extra := map[string]string{}
+	for _, value := range values {
+		for _, chunk := range strings.Split(value, ",") {
+			if strings.TrimSpace(chunk) == "" {
+				continue
+			}
+			key, val, ok := strings.Cut(chunk, "=")
+			key = strings.TrimSpace(key)
+			if !ok || key == "" {
+				return nil, fmt.Errorf("invalid matrix extra field %q, should be key=value", chunk)
+			}
+			extra[key] = val
+		}
+	}
+	return extra, nil
+}
+
+// NewMatrix creates an output writer of GitHub Actions strategy matrix.
+func NewMatrix(params types.Params, output io.Writer) (Matrix, error) {
+	keys, err := parseMatrixKeys(params.OutMatrixKeys)
+	if err != nil {
+		return Matrix{}, err
+	}
+	extra, err := parseMatrixExtra(params.OutMatrixExtra)
+	if err != nil {
+		return Matrix{}, err
+	}
+	for _, key := range keys {
+		if _, ok := extra[key.name]; ok {
+			return Matrix{}, fmt.Errorf("matrix extra field %q clashes with matrix key", key.name)
+		}
+	}
+	return Matrix{params: params, output: output, keys: keys, extra: extra}, nil
+}
+
+// Matrix writes versions as {"include":[{"version":"6.2.1","major":"6",...}]} on a single line,
+// so that it can be used with fromJSON as strategy.matrix of a job.
+type Matrix struct {
+	params types.Params
+	output io.Writer
+	keys   []matrixKey
+	extra  map[string]string
+}
+
+func (o Matrix) Write(versions version.Versions) error {
+	matrix := struct {
+		Include []map[string]string `json:"include"`
+	}{
+		Include: []map[string]string{},
+	}
+	for _, ver := range versions.Order(o.params.OutReverseOrder) {
+		entry := make(map[string]string, len(o.keys)+len(o.extra))
+		for key, value := range o.extra {
+			entry[key] = value
+		}
+		for _, key := range o.keys {
+			entry[key.name] = key.get(ver, !o.params.OutNoPrefix)
+		}
+		matrix.Include = append(matrix.Include, entry)
+	}
+	return json.NewEncoder(o.output).Encode(matrix)
+}
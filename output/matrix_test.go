@@ -0,0 +1,110 @@
+package output
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/scylladb-actions/get-version/types"
+	"github.com/scylladb-actions/get-version/version"
+)
+
+func TestMatrix(t *testing.T) {
+	newVersion := func(value string, metadata version.Metadata) version.Version {
+		ver := version.NewMust(value)
+		ver.SetPrefix("scylla-")
+		ver.SetMetadata(metadata)
+		return ver
+	}
+	versions := version.Versions{
+		newVersion("6.2.1", version.Metadata{"commit": "bbb"}),
+		newVersion("6.1.0-rc1", nil),
+	}
+
+	tcases := []struct {
+		name      string
+		params    types.Params
+		expected  string
+		expectErr bool
+	}{
+		{
+			name:   "default keys",
+			params: types.Params{OutMatrixKeys: "version,major,minor,patch"},
+			expected: `{"include":[{"major":"6","minor":"1","patch":"0-rc1","version":"scylla-6.1.0-rc1"},` +
+				`{"major":"6","minor":"2","patch":"1","version":"scylla-6.2.1"}]}` + "\n",
+		},
+		{
+			name:   "patch number",
+			params: types.Params{OutMatrixKeys: "patch,patch-number"},
+			expected: `{"include":[{"patch":"0-rc1","patch-number":"0"},` +
+				`{"patch":"1","patch-number":"1"}]}` + "\n",
+		},
+		{
+			name: "renamed keys, metadata and extra fields",
+			params: types.Params{
+				OutMatrixKeys:   "version=scylla-version, extra, metadata.commit=commit",
+				OutMatrixExtra:  []string{"os=ubuntu-24.04,arch=x86_64", "suite=dtest"},
+				OutNoPrefix:     true,
+				OutReverseOrder: true,
+			},
+			expected: `{"include":[{"arch":"x86_64","commit":"bbb","extra":"","os":"ubuntu-24.04",` +
+				`"scylla-version":"6.2.1","suite":"dtest"},{"arch":"x86_64","commit":"","extra":"-rc1",` +
+				`"os":"ubuntu-24.04","scylla-version":"6.1.0-rc1","suite":"dtest"}]}` + "\n",
+		},
+		{
+			name:      "unknown field",
+			params:    types.Params{OutMatrixKeys: "version,build"},
+			expectErr: true,
+		},
+		{
+			name:      "duplicate key",
+			params:    types.Params{OutMatrixKeys: "version,major=version"},
+			expectErr: true,
+		},
+		{
+			name:      "extra field without value",
+			params:    types.Params{OutMatrixKeys: "version", OutMatrixExtra: []string{"os"}},
+			expectErr: true,
+		},
+		{
+			name:      "extra field clashes with key",
+			params:    types.Params{OutMatrixKeys: "version", OutMatrixExtra: []string{"version=1"}},
+			expectErr: true,
+		},
+	}
+
+	for _, tcase := range tcases {
+		t.Run(tcase.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			out, err := NewMatrix(tcase.params, &buf)
+			if tcase.expectErr {
+				if err == nil {
+					t.Fatalf("expected error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if err = out.Write(versions); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if buf.String() != tcase.expected {
+				t.Fatalf("expected %s, got %s", tcase.expected, buf.String())
+			}
+		})
+	}
+}
+
+func TestMatrixNoVersions(t *testing.T) {
+	var buf bytes.Buffer
+	out, err := NewMatrix(types.Params{OutMatrixKeys: "version"}, &buf)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err = out.Write(nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if expected := `{"include":[]}` + "\n"; buf.String() != expected {
+		t.Fatalf("expected %s, got %s", expected, buf.String())
+	}
+}
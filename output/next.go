@@ -0,0 +1,64 @@
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/scylladb-actions/get-version/bump"
+	"github.com/scylladb-actions/get-version/types"
+)
+
+// NewNext creates a writer of the next release version, it writes to GitHub Action outputs with --out-as-action
+// and to stdout otherwise.
+func NewNext(params types.Params) (Next, error) {
+	if !params.OutAsAction {
+		return Next{params: params, output: os.Stdout}, nil
+	}
+	action, err := NewAction(params)
+	if err != nil {
+		return Next{}, err
+	}
+	return Next{params: params, action: &action}, nil
+}
+
+// Next writes the next release version, as text it is just the version, so that scripts can take it as is.
+// As GitHub Action outputs it is next-version and previous-version, prefixed with "<name>-"
+// unless --out-name is "versions".
+type Next struct {
+	params types.Params
+	output io.Writer
+	action *Action
+}
+
+// nextReport is the next version as it is written in json and yaml
+type nextReport struct {
+	Next     string         `json:"next-version" yaml:"next-version"`
+	Previous string         `json:"previous-version,omitempty" yaml:"previous-version,omitempty"`
+	Bump     types.BumpName `json:"bump" yaml:"bump"`
+}
+
+func (o Next) Write(result bump.Result) error {
+	report := nextReport{Next: versionString(o.params, result.Next), Bump: o.params.Bump}
+	if result.Previous != nil {
+		report.Previous = versionString(o.params, *result.Previous)
+	}
+	if o.action != nil {
+		return o.action.writeOutputs([]actionOutput{
+			{name: o.action.outputName("next-version"), value: report.Next},
+			{name: o.action.outputName("previous-version"), value: report.Previous},
+		})
+	}
+	switch o.params.OutFormat {
+	case types.OutputJSON:
+		return json.NewEncoder(o.output).Encode(report)
+	case types.OutputYAML:
+		return yaml.NewEncoder(o.output).Encode(report)
+	default:
+		_, err := fmt.Fprintln(o.output, report.Next)
+		return err
+	}
+}
@@ -0,0 +1,52 @@
+package output
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/scylladb-actions/get-version/bump"
+	"github.com/scylladb-actions/get-version/types"
+	"github.com/scylladb-actions/get-version/version"
+)
+
+func TestNext(t *testing.T) {
+	previous := version.NewMust("6.2.0-rc2")
+	previous.SetPrefix("scylla-")
+	next := version.NewMust("6.2.0-rc3")
+	next.SetPrefix("scylla-")
+	result := bump.Result{Previous: &previous, Next: next}
+
+	tcases := []struct {
+		name     string
+		params   types.Params
+		expected string
+	}{
+		{
+			name:     "text",
+			params:   types.Params{Bump: types.BumpRC},
+			expected: "scylla-6.2.0-rc3\n",
+		},
+		{
+			name:     "text without prefix",
+			params:   types.Params{Bump: types.BumpRC, OutNoPrefix: true},
+			expected: "6.2.0-rc3\n",
+		},
+		{
+			name:     "json",
+			params:   types.Params{Bump: types.BumpRC, OutFormat: types.OutputJSON},
+			expected: `{"next-version":"scylla-6.2.0-rc3","previous-version":"scylla-6.2.0-rc2","bump":"rc"}` + "\n",
+		},
+	}
+
+	for _, tcase := range tcases {
+		t.Run(tcase.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := (Next{params: tcase.params, output: &buf}).Write(result); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if buf.String() != tcase.expected {
+				t.Errorf("expected %q, got %q", tcase.expected, buf.String())
+			}
+		})
+	}
+}
@@ -66,37 +66,24 @@ func (o YAML) Write(versions version.Versions) error {
 }
 
 func NewOutput(params types.Params) (types.OutputType, error) {
-	output, err := getOutputDestination(params)
-	if err != nil {
-		return nil, err
+	if params.OutAsAction {
+		return NewAction(params)
 	}
+	return NewFormat(params, os.Stdout)
+}
 
+// NewFormat returns a writer of --out-format to output.
+func NewFormat(params types.Params, output io.Writer) (types.OutputType, error) {
 	switch params.OutFormat {
 	case types.OutputJSON:
 		return NewJSON(params, output), nil
 	case types.OutputYAML:
 		return NewYAML(params, output), nil
+	case types.OutputTemplate:
+		return NewTemplate(params, output)
+	case types.OutputMatrix:
+		return NewMatrix(params, output)
 	default:
 		return NewText(params, output), nil
 	}
 }
-
-func getOutputDestination(p types.Params) (*os.File, error) {
-	if p.OutAsAction {
-		// echo "versions=$(output)" >>"$GITHUB_OUTPUT"
-		gitHubActionOutput := os.Getenv("GITHUB_OUTPUT")
-		if gitHubActionOutput == "" {
-			return nil, fmt.Errorf("GITHUB_OUTPUT is not set")
-		}
-		writer, err := os.OpenFile(os.Getenv("GITHUB_OUTPUT"), os.O_APPEND|os.O_WRONLY, 0o644)
-		if err != nil {
-			return nil, fmt.Errorf("failed to open file %q: %w", gitHubActionOutput, err)
-		}
-		_, err = writer.Write([]byte("versions="))
-		if err != nil {
-			return nil, fmt.Errorf("failed to write to file %q: %w", gitHubActionOutput, err)
-		}
-		return writer, nil
-	}
-	return os.Stdout, nil
-}
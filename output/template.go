@@ -0,0 +1,127 @@
+package output
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"strings"
+	"text/template"
+
+	"github.com/scylladb-actions/get-version/types"
+	"github.com/scylladb-actions/get-version/version"
+)
+
+// templateVersion is a version as it is seen by --out-template
+type templateVersion struct {
+	Major    int
+	Minor    int
+	Patch    int
+	Extra    string
+	Prefix   string
+	Metadata version.Metadata
+	str      string
+}
+
+// String returns the version as other output formats print it, without prefix if --out-no-prefix is set
+func (v templateVersion) String() string {
+	return v.str
+}
+
+func newTemplateVersions(versions version.Versions, withPrefix bool) []templateVersion {
+	out := make([]templateVersion, len(versions))
+	for i, ver := range versions {
+		str := ver.String()
+		if !withPrefix {
+			str = ver.NoPrefixString()
+		}
+		out[i] = templateVersion{
+			Major:    ver.Major(),
+			Minor:    ver.Minor(),
+			Patch:    ver.Patch(),
+			Extra:    ver.Extra(),
+			Prefix:   ver.Prefix(),
+			Metadata: ver.Metadata(),
+			str:      str,
+		}
+	}
+	return out
+}
+
+var templateFuncs = template.FuncMap{
+	// join joins versions or strings with separator: {{ . | join "," }}
+	"join": func(sep string, items any) (string, error) {
+		value := reflect.ValueOf(items)
+		if value.Kind() != reflect.Slice {
+			return "", fmt.Errorf("join expects a list, got %T", items)
+		}
+		out := make([]string, value.Len())
+		for i := range out {
+			out[i] = fmt.Sprint(value.Index(i).Interface())
+		}
+		return strings.Join(out, sep), nil
+	},
+	"first": func(versions []templateVersion) (templateVersion, error) {
+		if len(versions) == 0 {
+			return templateVersion{}, fmt.Errorf("first: no versions")
+		}
+		return versions[0], nil
+	},
+	"last": func(versions []templateVersion) (templateVersion, error) {
+		if len(versions) == 0 {
+			return templateVersion{}, fmt.Errorf("last: no versions")
+		}
+		return versions[len(versions)-1], nil
+	},
+	// replace is ordered to be used in pipelines: {{ .String | replace "." "_" }}
+	"replace": func(old, new string, s any) string {
+		return strings.ReplaceAll(fmt.Sprint(s), old, new)
+	},
+}
+
+// parseTemplate parses --out-template, a value starting with @ is a path to the template file
+func parseTemplate(value string) (*template.Template, error) {
+	text := value
+	if path, ok := strings.CutPrefix(value, "@"); ok {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read output template: %w", err)
+		}
+		text = string(data)
+	}
+	tmpl, err := template.New("out-template").Option("missingkey=zero").Funcs(templateFuncs).Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse output template: %w", err)
+	}
+	return tmpl, nil
+}
+
+// NewTemplate creates an output writer that renders the list of versions through --out-template.
+func NewTemplate(params types.Params, output io.Writer) (Template, error) {
+	tmpl, err := parseTemplate(params.OutTemplate)
+	if err != nil {
+		return Template{}, err
+	}
+	return Template{params: params, output: output, tmpl: tmpl}, nil
+}
+
+type Template struct {
+	params types.Params
+	output io.Writer
+	tmpl   *template.Template
+}
+
+func (o Template) Write(versions version.Versions) error {
+	var buf bytes.Buffer
+	err := o.tmpl.Execute(&buf, newTemplateVersions(versions.Order(o.params.OutReverseOrder), !o.params.OutNoPrefix))
+	if err != nil {
+		return fmt.Errorf("failed to render output template: %w", err)
+	}
+	// Output always ends with new line, like other formats, so that it can be appended to $GITHUB_OUTPUT
+	if !bytes.HasSuffix(buf.Bytes(), []byte("\n")) {
+		buf.WriteByte('\n')
+	}
+	_, err = o.output.Write(buf.Bytes())
+	return err
+}
@@ -0,0 +1,111 @@
+package output
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/scylladb-actions/get-version/types"
+	"github.com/scylladb-actions/get-version/version"
+)
+
+func TestTemplate(t *testing.T) {
+	newVersion := func(value string, metadata version.Metadata) version.Version {
+		ver := version.NewMust(value)
+		ver.SetPrefix("scylla-")
+		ver.SetMetadata(metadata)
+		return ver
+	}
+	versions := version.Versions{
+		newVersion("6.2.1", version.Metadata{"commit": "bbb"}),
+		newVersion("6.1.0", nil),
+		newVersion("6.2.0-rc1", version.Metadata{"commit": "aaa"}),
+	}
+
+	templateFile := filepath.Join(t.TempDir(), "image.tmpl")
+	if err := os.WriteFile(templateFile, []byte("scylladb/scylla:{{ (last .).String }}\n"), 0o600); err != nil {
+		t.Fatalf("failed to write template: %v", err)
+	}
+
+	tcases := []struct {
+		name      string
+		params    types.Params
+		expected  string
+		expectErr bool
+	}{
+		{
+			name:     "join",
+			params:   types.Params{OutTemplate: `{{ . | join "," }}`},
+			expected: "scylla-6.1.0,scylla-6.2.0-rc1,scylla-6.2.1\n",
+		},
+		{
+			name: "fields",
+			params: types.Params{
+				OutTemplate: `{{ range . }}{{ .Prefix }} {{ .Major }} {{ .Minor }} {{ .Patch }} {{ .Extra }};{{ end }}`,
+			},
+			expected: "scylla- 6 1 0 ;scylla- 6 2 0 -rc1;scylla- 6 2 1 ;\n",
+		},
+		{
+			name: "no prefix and reverse order",
+			params: types.Params{
+				OutTemplate:     `-Dscylla.version={{ (first .).String }}`,
+				OutNoPrefix:     true,
+				OutReverseOrder: true,
+			},
+			expected: "-Dscylla.version=6.2.1\n",
+		},
+		{
+			name:     "metadata",
+			params:   types.Params{OutTemplate: `{{ range . }}{{ .String }}={{ .Metadata.commit }} {{ end }}`},
+			expected: "scylla-6.1.0= scylla-6.2.0-rc1=aaa scylla-6.2.1=bbb \n",
+		},
+		{
+			name:     "replace",
+			params:   types.Params{OutTemplate: `{{ (last .).String | replace "." "_" }}`},
+			expected: "scylla-6_2_1\n",
+		},
+		{
+			name:     "template file",
+			params:   types.Params{OutTemplate: "@" + templateFile},
+			expected: "scylladb/scylla:scylla-6.2.1\n",
+		},
+		{
+			name:      "unknown function",
+			params:    types.Params{OutTemplate: `{{ . | sort }}`},
+			expectErr: true,
+		},
+	}
+
+	for _, tcase := range tcases {
+		t.Run(tcase.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			out, err := NewTemplate(tcase.params, &buf)
+			if tcase.expectErr {
+				if err == nil {
+					t.Fatalf("expected error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if err = out.Write(versions); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if buf.String() != tcase.expected {
+				t.Fatalf("expected %q, got %q", tcase.expected, buf.String())
+			}
+		})
+	}
+}
+
+func TestTemplateNoVersions(t *testing.T) {
+	out, err := NewTemplate(types.Params{OutTemplate: `{{ (last .).String }}`}, &bytes.Buffer{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err = out.Write(nil); err == nil {
+		t.Fatalf("expected error for last of no versions")
+	}
+}
@@ -0,0 +1,78 @@
+package pin
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// fromLine matches FROM instruction with optional flags like --platform, group 1 is the image reference
+var fromLine = regexp.MustCompile(`(?im)^[ \t]*FROM[ \t]+(?:--\S+[ \t]+)*(\S+)`)
+
+// Dockerfile finds tag of Image in FROM lines, the first tagged image when Image is empty.
+// Image is compared without docker.io and library/ parts, so ubuntu matches docker.io/library/ubuntu.
+// When the tag is a variable, like ${SCYLLA_VERSION}, the pin is default value of ARG instruction with that name.
+// When no FROM line has the image, Image is taken as name of ARG instruction with default value,
+// like ARG SCYLLA_VERSION=6.1.3.
+type Dockerfile struct {
+	Image string
+}
+
+func (l Dockerfile) Locate(content []byte) (Pin, error) {
+	for _, match := range fromLine.FindAllSubmatchIndex(content, -1) {
+		start, end := match[2], match[3]
+		ref := string(content[start:end])
+		if digest := strings.IndexByte(ref, '@'); digest >= 0 {
+			ref = ref[:digest]
+		}
+		colon := strings.LastIndexByte(ref, ':')
+		if colon < 0 || colon < strings.LastIndexByte(ref, '/') {
+			continue
+		}
+		if l.Image != "" && normalizeImage(ref[:colon]) != normalizeImage(l.Image) {
+			continue
+		}
+		tag := ref[colon+1:]
+		if name, ok := tagVariable(tag); ok {
+			if p, found := locateArg(content, name); found {
+				return p, nil
+			}
+			return Pin{}, fmt.Errorf("%w: ARG %q of image %q tag has no default value", ErrNotFound, name, ref[:colon])
+		}
+		return newPin(content, start+colon+1, tag), nil
+	}
+	if l.Image == "" {
+		return Pin{}, fmt.Errorf("%w: no FROM line with a tagged image", ErrNotFound)
+	}
+	if p, found := locateArg(content, l.Image); found {
+		return p, nil
+	}
+	return Pin{}, fmt.Errorf("%w: no FROM line with image %q and no ARG with that name", ErrNotFound, l.Image)
+}
+
+// tagVariable returns name of variable the tag consists of, like SCYLLA_VERSION of ${SCYLLA_VERSION} or $SCYLLA_VERSION
+func tagVariable(tag string) (string, bool) {
+	if name, ok := strings.CutPrefix(tag, "${"); ok {
+		name, ok = strings.CutSuffix(name, "}")
+		return name, ok && name != ""
+	}
+	name, ok := strings.CutPrefix(tag, "$")
+	return name, ok && name != ""
+}
+
+// locateArg finds default value of ARG instruction with the name
+func locateArg(content []byte, name string) (Pin, bool) {
+	argLine := regexp.MustCompile(`(?m)^[ \t]*(?i:ARG)[ \t]+` + regexp.QuoteMeta(name) + `=["']?([^"'\s]+)`)
+	match := argLine.FindSubmatchIndex(content)
+	if match == nil {
+		return Pin{}, false
+	}
+	return newPin(content, match[2], string(content[match[2]:match[3]])), true
+}
+
+func normalizeImage(image string) string {
+	for _, registry := range []string{"docker.io/", "index.docker.io/", "registry-1.docker.io/"} {
+		image = strings.TrimPrefix(image, registry)
+	}
+	return strings.TrimPrefix(image, "library/")
+}
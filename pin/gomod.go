@@ -0,0 +1,20 @@
+package pin
+
+import (
+	"fmt"
+	"regexp"
+)
+
+// GoMod finds version of Module in require directives of go.mod, both single line and block ones.
+type GoMod struct {
+	Module string
+}
+
+func (l GoMod) Locate(content []byte) (Pin, error) {
+	require := regexp.MustCompile(`(?m)^[ \t]*(?:require[ \t]+)?` + regexp.QuoteMeta(l.Module) + `[ \t]+(v\S+)`)
+	match := require.FindSubmatchIndex(content)
+	if match == nil {
+		return Pin{}, fmt.Errorf("%w: module %q is not required", ErrNotFound, l.Module)
+	}
+	return newPin(content, match[2], string(content[match[2]:match[3]])), nil
+}
@@ -0,0 +1,86 @@
+package pin
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// packageJSONDependencies are sections of package.json that pin versions of packages
+var packageJSONDependencies = []string{"dependencies", "devDependencies", "peerDependencies", "optionalDependencies"}
+
+// PackageJSON finds version of package Name in dependencies of package.json, range operators like ^ are left out.
+type PackageJSON struct {
+	Name string
+}
+
+func (l PackageJSON) Locate(content []byte) (Pin, error) {
+	decoder := json.NewDecoder(bytes.NewReader(content))
+	if err := expectDelim(decoder, '{'); err != nil {
+		return Pin{}, err
+	}
+	for decoder.More() {
+		key, err := decoder.Token()
+		if err != nil {
+			return Pin{}, fmt.Errorf("failed to parse package.json: %w", err)
+		}
+		if section, _ := key.(string); !slices.Contains(packageJSONDependencies, section) {
+			var skip json.RawMessage
+			if err = decoder.Decode(&skip); err != nil {
+				return Pin{}, fmt.Errorf("failed to parse package.json: %w", err)
+			}
+			continue
+		}
+		p, err := l.locateIn(content, decoder)
+		if !errors.Is(err, ErrNotFound) {
+			return p, err
+		}
+	}
+	return Pin{}, fmt.Errorf("%w: package %q is not a dependency", ErrNotFound, l.Name)
+}
+
+// locateIn looks for the package in a dependencies object, the decoder is positioned at its start
+func (l PackageJSON) locateIn(content []byte, decoder *json.Decoder) (Pin, error) {
+	if err := expectDelim(decoder, '{'); err != nil {
+		return Pin{}, err
+	}
+	for decoder.More() {
+		name, err := decoder.Token()
+		if err != nil {
+			return Pin{}, fmt.Errorf("failed to parse package.json: %w", err)
+		}
+		var value json.RawMessage
+		if err = decoder.Decode(&value); err != nil {
+			return Pin{}, fmt.Errorf("failed to parse package.json: %w", err)
+		}
+		if name != l.Name || len(value) < 2 || value[0] != '"' {
+			continue
+		}
+		raw := string(value[1 : len(value)-1])
+		if strings.Contains(raw, `\`) {
+			return Pin{}, fmt.Errorf("version %s of package %q is escaped, it can't be located", value, l.Name)
+		}
+		// The decoder is right after the value, the version starts after its opening quote
+		start := int(decoder.InputOffset()) - len(value) + 1
+		trimmed, skipped := trimOperators(raw)
+		return newPin(content, start+skipped, trimmed), nil
+	}
+	if _, err := decoder.Token(); err != nil {
+		return Pin{}, fmt.Errorf("failed to parse package.json: %w", err)
+	}
+	return Pin{}, ErrNotFound
+}
+
+func expectDelim(decoder *json.Decoder, delim json.Delim) error {
+	token, err := decoder.Token()
+	if err != nil {
+		return fmt.Errorf("failed to parse package.json: %w", err)
+	}
+	if token != delim {
+		return fmt.Errorf("failed to parse package.json: expected %q, got %v", delim, token)
+	}
+	return nil
+}
@@ -0,0 +1,101 @@
+package pin
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Path finds a scalar in YAML or JSON by dot separated keys and sequence indexes, like jobs.test.env.VERSION
+// or services.0.tag. The pin is the whole scalar, Within narrows it down to a part, like tag of an image.
+type Path struct {
+	Path string
+}
+
+func (l Path) Locate(content []byte) (Pin, error) {
+	var document yaml.Node
+	if err := yaml.Unmarshal(content, &document); err != nil {
+		return Pin{}, fmt.Errorf("failed to parse document: %w", err)
+	}
+	node := &document
+	if node.Kind == yaml.DocumentNode && len(node.Content) != 0 {
+		node = node.Content[0]
+	}
+	for _, key := range strings.Split(strings.TrimPrefix(l.Path, "."), ".") {
+		node = pathChild(node, key)
+		if node == nil {
+			return Pin{}, fmt.Errorf("%w: no value at path %q", ErrNotFound, l.Path)
+		}
+	}
+	if node.Kind != yaml.ScalarNode {
+		return Pin{}, fmt.Errorf("value at path %q is not a scalar", l.Path)
+	}
+
+	offset := offsetOf(content, node.Line, node.Column)
+	if node.Style&(yaml.DoubleQuotedStyle|yaml.SingleQuotedStyle) != 0 {
+		offset++
+	}
+	if offset+len(node.Value) > len(content) || string(content[offset:offset+len(node.Value)]) != node.Value {
+		return Pin{}, fmt.Errorf("value at path %q is escaped or multi-line, it can't be located", l.Path)
+	}
+	return newPin(content, offset, node.Value), nil
+}
+
+func pathChild(node *yaml.Node, key string) *yaml.Node {
+	if node.Kind == yaml.AliasNode {
+		node = node.Alias
+	}
+	switch node.Kind {
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			if node.Content[i].Value == key {
+				return node.Content[i+1]
+			}
+		}
+	case yaml.SequenceNode:
+		index, err := strconv.Atoi(key)
+		if err == nil && index >= 0 && index < len(node.Content) {
+			return node.Content[index]
+		}
+	}
+	return nil
+}
+
+// offsetOf converts 1-based line and column in characters, as yaml reports them, to byte offset
+func offsetOf(content []byte, line, column int) int {
+	offset := 0
+	for ; line > 1; line-- {
+		next := bytes.IndexByte(content[offset:], '\n')
+		if next < 0 {
+			return len(content)
+		}
+		offset += next + 1
+	}
+	for ; column > 1 && offset < len(content); column-- {
+		_, size := utf8.DecodeRune(content[offset:])
+		offset += size
+	}
+	return offset
+}
+
+// Within finds version by Inner in the value found by Outer, like a tag in image reference at a YAML path.
+type Within struct {
+	Outer Locator
+	Inner Regexp
+}
+
+func (l Within) Locate(content []byte) (Pin, error) {
+	outer, err := l.Outer.Locate(content)
+	if err != nil {
+		return Pin{}, err
+	}
+	inner, err := l.Inner.Locate([]byte(outer.Value))
+	if err != nil {
+		return Pin{}, fmt.Errorf("%s: %w", outer.Value, err)
+	}
+	return newPin(content, outer.Offset+inner.Offset, inner.Value), nil
+}
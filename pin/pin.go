@@ -0,0 +1,96 @@
+// Package pin finds versions pinned in files, like the tag of an image in a Dockerfile FROM line.
+package pin
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ErrNotFound is returned when a file has no pinned version the locator looks for.
+var ErrNotFound = errors.New("pinned version is not found")
+
+// Pin is a version pinned in a file. Offset and Value cover only the version itself,
+// without operators like ^ in package.json, so that it can be replaced in place.
+type Pin struct {
+	Path   string
+	Line   int
+	Offset int
+	Value  string
+}
+
+func (p Pin) String() string {
+	return fmt.Sprintf("%s:%d: %s", p.Path, p.Line, p.Value)
+}
+
+// Locator finds a pinned version in file content.
+type Locator interface {
+	Locate(content []byte) (Pin, error)
+}
+
+// Find reads file at path and finds a pinned version in it.
+func Find(path string, locator Locator) (Pin, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return Pin{}, fmt.Errorf("failed to read file %q: %w", path, err)
+	}
+	p, err := locator.Locate(content)
+	if err != nil {
+		return Pin{}, fmt.Errorf("%s: %w", path, err)
+	}
+	p.Path = path
+	return p, nil
+}
+
+// ForFile returns a locator for well known files by their name: Dockerfile, go.mod, pom.xml and package.json.
+// name is what version is pinned for: image, module, artifact ID or package name.
+func ForFile(path, name string) (Locator, error) {
+	base := filepath.Base(path)
+	switch {
+	case base == "go.mod":
+		if name == "" {
+			return nil, fmt.Errorf("module name is required to find its version in %q", path)
+		}
+		return GoMod{Module: name}, nil
+	case base == "pom.xml":
+		if name == "" {
+			return nil, fmt.Errorf("artifact ID is required to find its version in %q", path)
+		}
+		return Pom{ArtifactID: name}, nil
+	case base == "package.json":
+		if name == "" {
+			return nil, fmt.Errorf("package name is required to find its version in %q", path)
+		}
+		return PackageJSON{Name: name}, nil
+	case isDockerfile(base):
+		return Dockerfile{Image: name}, nil
+	}
+	return nil, fmt.Errorf("can't tell how to find version in %q, provide a pattern", path)
+}
+
+func isDockerfile(base string) bool {
+	lower := strings.ToLower(base)
+	return lower == "dockerfile" || lower == "containerfile" ||
+		strings.HasPrefix(lower, "dockerfile.") || strings.HasSuffix(lower, ".dockerfile")
+}
+
+// newPin returns pin of value found at offset of content
+func newPin(content []byte, offset int, value string) Pin {
+	return Pin{
+		Line:   bytes.Count(content[:offset], []byte("\n")) + 1,
+		Offset: offset,
+		Value:  value,
+	}
+}
+
+// operators are version range operators of package managers, they are not part of the version
+const operators = "^~=<> "
+
+// trimOperators returns value without leading range operators and how many bytes were trimmed
+func trimOperators(value string) (string, int) {
+	trimmed := strings.TrimLeft(value, operators)
+	return trimmed, len(value) - len(trimmed)
+}
@@ -0,0 +1,268 @@
+package pin
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const testPom = `<?xml version="1.0" encoding="UTF-8"?>
+<project>
+  <artifactId>app</artifactId>
+  <version>1.0.0</version>
+  <properties>
+    <driver.version>4.17.0.0</driver.version>
+  </properties>
+  <dependencies>
+    <dependency>
+      <groupId>com.scylladb</groupId>
+      <artifactId>java-driver-core</artifactId>
+      <version>${driver.version}</version>
+    </dependency>
+    <dependency>
+      <groupId>com.scylladb</groupId>
+      <artifactId>scylla-cdc-lib</artifactId>
+      <version>
+        1.3.2
+      </version>
+    </dependency>
+  </dependencies>
+</project>
+`
+
+const testPackageJSON = `{
+  "name": "app",
+  "version": "1.0.0",
+  "scripts": {"test": "jest"},
+  "dependencies": {
+    "left-pad": "1.3.0"
+  },
+  "devDependencies": {
+    "typescript": "^5.4.2"
+  }
+}
+`
+
+func TestLocators(t *testing.T) {
+	tcases := []struct {
+		name     string
+		locator  Locator
+		content  string
+		expected string
+		line     int
+	}{
+		{
+			name:     "dockerfile image",
+			locator:  Dockerfile{Image: "scylladb/scylla"},
+			content:  "FROM golang:1.25 AS build\nFROM --platform=linux/amd64 docker.io/scylladb/scylla:6.1.3\n",
+			expected: "6.1.3",
+			line:     2,
+		},
+		{
+			name:     "dockerfile official image with digest",
+			locator:  Dockerfile{Image: "ubuntu"},
+			content:  "from docker.io/library/ubuntu:24.04@sha256:abcdef as base\n",
+			expected: "24.04",
+			line:     1,
+		},
+		{
+			name:     "dockerfile first tagged image",
+			locator:  Dockerfile{},
+			content:  "FROM scratch\nFROM localhost:5000/alpine:3.20\n",
+			expected: "3.20",
+			line:     2,
+		},
+		{
+			name:     "go.mod require block",
+			locator:  GoMod{Module: "github.com/docker/cli"},
+			content:  "module app\n\nrequire (\n\tgithub.com/docker/cli v28.3.3+incompatible\n)\n",
+			expected: "v28.3.3+incompatible",
+			line:     4,
+		},
+		{
+			name:     "go.mod single require",
+			locator:  GoMod{Module: "gopkg.in/yaml.v3"},
+			content:  "module app\n\nrequire gopkg.in/yaml.v3 v3.0.1 // indirect\n",
+			expected: "v3.0.1",
+			line:     3,
+		},
+		{
+			name:     "pom property",
+			locator:  Pom{ArtifactID: "java-driver-core"},
+			content:  testPom,
+			expected: "4.17.0.0",
+			line:     6,
+		},
+		{
+			name:     "pom version with spaces",
+			locator:  Pom{ArtifactID: "scylla-cdc-lib"},
+			content:  testPom,
+			expected: "1.3.2",
+			line:     18,
+		},
+		{
+			name:     "package.json dependency",
+			locator:  PackageJSON{Name: "left-pad"},
+			content:  testPackageJSON,
+			expected: "1.3.0",
+			line:     6,
+		},
+		{
+			name:     "package.json range",
+			locator:  PackageJSON{Name: "typescript"},
+			content:  testPackageJSON,
+			expected: "5.4.2",
+			line:     9,
+		},
+		{
+			name:     "dockerfile arg",
+			locator:  Dockerfile{Image: "SCYLLA_VERSION"},
+			content:  "ARG SCYLLA_VERSION=\"6.1.3\"\nFROM scylladb/scylla:${SCYLLA_VERSION}\n",
+			expected: "6.1.3",
+			line:     1,
+		},
+		{
+			name:     "dockerfile image tag from arg",
+			locator:  Dockerfile{Image: "scylladb/scylla"},
+			content:  "ARG SCYLLA_VERSION=6.1.3\nFROM scylladb/scylla:${SCYLLA_VERSION}\n",
+			expected: "6.1.3",
+			line:     1,
+		},
+		{
+			name:     "dockerfile image tag from arg without braces",
+			locator:  Dockerfile{Image: "scylladb/scylla"},
+			content:  "FROM golang:1.25 AS build\nARG SCYLLA_VERSION='6.1.3'\nFROM scylladb/scylla:$SCYLLA_VERSION\n",
+			expected: "6.1.3",
+			line:     2,
+		},
+		{
+			name:     "pom property by name",
+			locator:  Pom{ArtifactID: "driver.version"},
+			content:  testPom,
+			expected: "4.17.0.0",
+			line:     6,
+		},
+		{
+			name:     "yaml path",
+			locator:  Path{Path: "jobs.test.env.SCYLLA_VERSION"},
+			content:  "jobs:\n  test:\n    env:\n      SCYLLA_VERSION: \"6.1.3\" # pinned\n",
+			expected: "6.1.3",
+			line:     4,
+		},
+		{
+			name:     "yaml sequence",
+			locator:  Path{Path: ".images.1"},
+			content:  "images:\n  - ubuntu:24.04\n  - 'scylladb/scylla:6.1.3'\n",
+			expected: "scylladb/scylla:6.1.3",
+			line:     3,
+		},
+		{
+			name:     "json path",
+			locator:  Path{Path: "engines.node"},
+			content:  `{"name": "app", "engines": {"node": "22.3.0"}}`,
+			expected: "22.3.0",
+			line:     1,
+		},
+		{
+			name:     "within yaml path",
+			locator:  Within{Outer: Path{Path: "services.scylla.image"}, Inner: mustRegexp(t, `:(.+)$`)},
+			content:  "services:\n  scylla:\n    image: scylladb/scylla:6.1.3\n",
+			expected: "6.1.3",
+			line:     3,
+		},
+		{
+			name:     "regexp",
+			locator:  mustRegexp(t, `SCYLLA_VERSION=(?P<version>\S+)`),
+			content:  "ARG FOO=1\nENV SCYLLA_VERSION=2025.1.0\n",
+			expected: "2025.1.0",
+			line:     2,
+		},
+	}
+
+	for _, tcase := range tcases {
+		t.Run(tcase.name, func(t *testing.T) {
+			p, err := tcase.locator.Locate([]byte(tcase.content))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if p.Value != tcase.expected {
+				t.Errorf("expected %q, got %q", tcase.expected, p.Value)
+			}
+			if got := tcase.content[p.Offset : p.Offset+len(p.Value)]; got != p.Value {
+				t.Errorf("offset %d points at %q instead of %q", p.Offset, got, p.Value)
+			}
+			if p.Line != tcase.line {
+				t.Errorf("expected line %d, got %d", tcase.line, p.Line)
+			}
+		})
+	}
+}
+
+func TestLocatorsNotFound(t *testing.T) {
+	tcases := []struct {
+		name    string
+		locator Locator
+		content string
+	}{
+		{name: "dockerfile", locator: Dockerfile{Image: "scylladb/scylla"}, content: "FROM scylladb/scylla-manager:3.3.0\n"},
+		{name: "dockerfile untagged", locator: Dockerfile{}, content: "FROM ubuntu\n"},
+		{
+			name:    "dockerfile tag arg without default",
+			locator: Dockerfile{Image: "scylladb/scylla"},
+			content: "ARG SCYLLA_VERSION\nFROM scylladb/scylla:${SCYLLA_VERSION}\n",
+		},
+		{name: "go.mod", locator: GoMod{Module: "github.com/docker/cli"}, content: "module github.com/docker/cli\n"},
+		{name: "pom", locator: Pom{ArtifactID: "app"}, content: testPom},
+		{name: "package.json", locator: PackageJSON{Name: "app"}, content: testPackageJSON},
+		{name: "regexp", locator: mustRegexp(t, `VERSION=(\S+)`), content: "FROM ubuntu\n"},
+		{name: "path", locator: Path{Path: "jobs.build"}, content: "jobs:\n  test: {}\n"},
+	}
+
+	for _, tcase := range tcases {
+		t.Run(tcase.name, func(t *testing.T) {
+			_, err := tcase.locator.Locate([]byte(tcase.content))
+			if !errors.Is(err, ErrNotFound) {
+				t.Fatalf("expected ErrNotFound, got %v", err)
+			}
+		})
+	}
+}
+
+func TestFind(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "build.Dockerfile")
+	if err := os.WriteFile(path, []byte("FROM scylladb/scylla:6.1.3\n"), 0o644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+	locator, err := ForFile(path, "scylladb/scylla")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	p, err := Find(path, locator)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if p.Path != path || p.Value != "6.1.3" {
+		t.Errorf("unexpected pin %s", p)
+	}
+
+	if _, err = ForFile(filepath.Join(dir, "versions.txt"), ""); err == nil {
+		t.Errorf("expected error for unknown file")
+	}
+	if _, err = ForFile(filepath.Join(dir, "package.json"), ""); err == nil {
+		t.Errorf("expected error for package.json without package name")
+	}
+	if _, err = NewRegexp(`(\d+)\.(\d+)`); err == nil {
+		t.Errorf("expected error for pattern with two groups")
+	}
+}
+
+func mustRegexp(t *testing.T, pattern string) Regexp {
+	t.Helper()
+	locator, err := NewRegexp(pattern)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return locator
+}
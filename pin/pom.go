@@ -0,0 +1,110 @@
+package pin
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Pom finds version of ArtifactID among dependencies, plugins and parent of pom.xml.
+// When the version is a ${property}, the pin is the value of that property.
+// When no artifact has the ID, it is taken as name of a property, like scylla.version.
+type Pom struct {
+	ArtifactID string
+}
+
+// pomText is a text node of pom.xml with its offset
+type pomText struct {
+	value  string
+	offset int
+}
+
+// pomCoordinates is an element with artifactId and version, like dependency
+type pomCoordinates struct {
+	artifactID string
+	version    pomText
+	found      bool
+}
+
+var pomCoordinateElements = map[string]bool{"dependency": true, "plugin": true, "parent": true, "extension": true}
+
+func (l Pom) Locate(content []byte) (Pin, error) {
+	decoder := xml.NewDecoder(bytes.NewReader(content))
+	var (
+		path       []string
+		holders    []*pomCoordinates
+		properties = map[string]pomText{}
+		found      *pomCoordinates
+	)
+	for {
+		offset := int(decoder.InputOffset())
+		token, err := decoder.Token()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return Pin{}, fmt.Errorf("failed to parse pom.xml: %w", err)
+		}
+		switch token := token.(type) {
+		case xml.StartElement:
+			path = append(path, token.Name.Local)
+			if pomCoordinateElements[token.Name.Local] {
+				holders = append(holders, &pomCoordinates{})
+			}
+		case xml.EndElement:
+			if pomCoordinateElements[token.Name.Local] && len(holders) != 0 {
+				holder := holders[len(holders)-1]
+				holders = holders[:len(holders)-1]
+				if found == nil && holder.artifactID == l.ArtifactID && holder.found {
+					found = holder
+				}
+			}
+			path = path[:len(path)-1]
+		case xml.CharData:
+			text := pomTextAt(string(token), offset)
+			switch {
+			case len(path) == 3 && path[0] == "project" && path[1] == "properties":
+				properties[path[2]] = text
+			case len(path) >= 2 && len(holders) != 0 && pomCoordinateElements[path[len(path)-2]]:
+				holder := holders[len(holders)-1]
+				switch path[len(path)-1] {
+				case "artifactId":
+					holder.artifactID = text.value
+				case "version":
+					holder.version, holder.found = text, true
+				}
+			}
+		}
+	}
+	var text pomText
+	switch property, ok := properties[l.ArtifactID]; {
+	case found != nil:
+		text = found.version
+	case ok:
+		text = property
+	default:
+		return Pin{}, fmt.Errorf("%w: neither artifact nor property %q has version", ErrNotFound, l.ArtifactID)
+	}
+	if name, ok := strings.CutPrefix(text.value, "${"); ok && strings.HasSuffix(name, "}") {
+		name = strings.TrimSuffix(name, "}")
+		property, ok := properties[name]
+		if !ok {
+			return Pin{}, fmt.Errorf("%w: property %q of %q version is not defined", ErrNotFound, name, l.ArtifactID)
+		}
+		text = property
+	}
+	if string(content[text.offset:text.offset+len(text.value)]) != text.value {
+		return Pin{}, fmt.Errorf("version %q of artifact %q is escaped, it can't be located", text.value, l.ArtifactID)
+	}
+	return newPin(content, text.offset, text.value), nil
+}
+
+// pomTextAt returns text without surrounding spaces and with offset of its first non-space character
+func pomTextAt(raw string, offset int) pomText {
+	trimmed := strings.TrimLeft(raw, " \t\r\n")
+	offset += len(raw) - len(trimmed)
+	return pomText{value: strings.TrimRight(trimmed, " \t\r\n"), offset: offset}
+}
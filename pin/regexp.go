@@ -0,0 +1,36 @@
+package pin
+
+import (
+	"fmt"
+	"regexp"
+)
+
+// Regexp finds version captured by a regular expression, by group named "version" or by the only group.
+type Regexp struct {
+	pattern *regexp.Regexp
+	group   int
+}
+
+// NewRegexp compiles pattern, it should have a group named "version" or a single group.
+func NewRegexp(pattern string) (Regexp, error) {
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return Regexp{}, fmt.Errorf("failed to parse pattern %q: %w", pattern, err)
+	}
+	if group := re.SubexpIndex("version"); group > 0 {
+		return Regexp{pattern: re, group: group}, nil
+	}
+	if re.NumSubexp() != 1 {
+		return Regexp{}, fmt.Errorf("pattern %q should have a group named version or a single group", pattern)
+	}
+	return Regexp{pattern: re, group: 1}, nil
+}
+
+func (l Regexp) Locate(content []byte) (Pin, error) {
+	match := l.pattern.FindSubmatchIndex(content)
+	if match == nil || match[2*l.group] < 0 {
+		return Pin{}, fmt.Errorf("%w by pattern %q", ErrNotFound, l.pattern)
+	}
+	start, end := match[2*l.group], match[2*l.group+1]
+	return newPin(content, start, string(content[start:end])), nil
+}
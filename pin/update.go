@@ -0,0 +1,123 @@
+package pin
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Replace returns copy of content with the pinned version replaced by value.
+// It fails when content at the pin is not the pinned version, e.g. the file was changed after it was located.
+func (p Pin) Replace(content []byte, value string) ([]byte, error) {
+	end := p.Offset + len(p.Value)
+	if end > len(content) || string(content[p.Offset:end]) != p.Value {
+		return nil, fmt.Errorf("%s: pinned version has changed", p)
+	}
+	out := make([]byte, 0, len(content)-len(p.Value)+len(value))
+	out = append(out, content[:p.Offset]...)
+	out = append(out, value...)
+	return append(out, content[end:]...), nil
+}
+
+// Update replaces the pinned version in its file with value, the file is left as is when dryRun is set.
+// It returns file content before and after the update.
+func Update(p Pin, value string, dryRun bool) (before, after []byte, err error) {
+	info, err := os.Stat(p.Path)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to stat file %q: %w", p.Path, err)
+	}
+	before, err = os.ReadFile(p.Path)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read file %q: %w", p.Path, err)
+	}
+	after, err = p.Replace(before, value)
+	if err != nil || dryRun {
+		return before, after, err
+	}
+	if err = os.WriteFile(p.Path, after, info.Mode().Perm()); err != nil {
+		return nil, nil, fmt.Errorf("failed to write file %q: %w", p.Path, err)
+	}
+	return before, after, nil
+}
+
+// diffContext is the number of unchanged lines around changes, the same as diff -u uses
+const diffContext = 3
+
+// UnifiedDiff returns diff of two versions of file at path in unified format, empty when they are equal.
+// Changed lines are expected to be in one place, which is how Replace changes files, so there is a single hunk.
+func UnifiedDiff(path string, before, after []byte) string {
+	if bytes.Equal(before, after) {
+		return ""
+	}
+	oldLines, newLines := splitLines(before), splitLines(after)
+	prefix := 0
+	for prefix < len(oldLines) && prefix < len(newLines) && oldLines[prefix] == newLines[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(oldLines)-prefix && suffix < len(newLines)-prefix &&
+		oldLines[len(oldLines)-1-suffix] == newLines[len(newLines)-1-suffix] {
+		suffix++
+	}
+	start := max(prefix-diffContext, 0)
+	oldEnd := min(len(oldLines)-suffix+diffContext, len(oldLines))
+	newEnd := min(len(newLines)-suffix+diffContext, len(newLines))
+
+	var out strings.Builder
+	path = diffPath(path)
+	fmt.Fprintf(&out, "--- a/%s\n+++ b/%s\n", path, path)
+	fmt.Fprintf(&out, "@@ -%s +%s @@\n", hunkRange(start, oldEnd-start), hunkRange(start, newEnd-start))
+	for _, line := range oldLines[start:prefix] {
+		writeDiffLine(&out, ' ', line)
+	}
+	for _, line := range oldLines[prefix : len(oldLines)-suffix] {
+		writeDiffLine(&out, '-', line)
+	}
+	for _, line := range newLines[prefix : len(newLines)-suffix] {
+		writeDiffLine(&out, '+', line)
+	}
+	for _, line := range oldLines[len(oldLines)-suffix : oldEnd] {
+		writeDiffLine(&out, ' ', line)
+	}
+	return out.String()
+}
+
+// diffPath returns path relative to the working directory for diff headers, paths outside of it lose the leading /,
+// so that the diff applies with patch -p1 from the working directory or from the root
+func diffPath(path string) string {
+	path = filepath.Clean(path)
+	if filepath.IsAbs(path) {
+		if wd, err := os.Getwd(); err == nil {
+			if rel, err := filepath.Rel(wd, path); err == nil && filepath.IsLocal(rel) {
+				return filepath.ToSlash(rel)
+			}
+		}
+	}
+	return strings.TrimLeft(filepath.ToSlash(path), "/")
+}
+
+// splitLines splits content to lines keeping line ends, so that a missing newline at the end is seen as a change
+func splitLines(content []byte) []string {
+	lines := strings.SplitAfter(string(content), "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+func hunkRange(start, count int) string {
+	if count == 0 {
+		return fmt.Sprintf("%d,0", start)
+	}
+	return fmt.Sprintf("%d,%d", start+1, count)
+}
+
+func writeDiffLine(out *strings.Builder, kind byte, line string) {
+	out.WriteByte(kind)
+	out.WriteString(line)
+	if !strings.HasSuffix(line, "\n") {
+		out.WriteString("\n\\ No newline at end of file\n")
+	}
+}
@@ -0,0 +1,109 @@
+package pin
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestUnifiedDiff(t *testing.T) {
+	tcases := []struct {
+		name     string
+		before   string
+		after    string
+		expected string
+	}{
+		{
+			name:     "equal",
+			before:   "a\nb\n",
+			after:    "a\nb\n",
+			expected: "",
+		},
+		{
+			name:   "context",
+			before: "1\n2\n3\n4\n5\n6\n7\n8\n9\n",
+			after:  "1\n2\n3\n4\nfive\n6\n7\n8\n9\n",
+			expected: "--- a/f\n+++ b/f\n@@ -2,7 +2,7 @@\n" +
+				" 2\n 3\n 4\n-5\n+five\n 6\n 7\n 8\n",
+		},
+		{
+			name:   "first line without newline at end",
+			before: "FROM scylladb/scylla:6.1.3",
+			after:  "FROM scylladb/scylla:6.2.1",
+			expected: "--- a/f\n+++ b/f\n@@ -1,1 +1,1 @@\n" +
+				"-FROM scylladb/scylla:6.1.3\n\\ No newline at end of file\n" +
+				"+FROM scylladb/scylla:6.2.1\n\\ No newline at end of file\n",
+		},
+	}
+
+	for _, tcase := range tcases {
+		t.Run(tcase.name, func(t *testing.T) {
+			if got := UnifiedDiff("f", []byte(tcase.before), []byte(tcase.after)); got != tcase.expected {
+				t.Errorf("expected:\n%s\ngot:\n%s", tcase.expected, got)
+			}
+		})
+	}
+}
+
+func TestUnifiedDiffPath(t *testing.T) {
+	dir := t.TempDir()
+	t.Chdir(dir)
+
+	tcases := []struct {
+		path     string
+		expected string
+	}{
+		{path: "Dockerfile", expected: "Dockerfile"},
+		{path: "./deploy/../Dockerfile", expected: "Dockerfile"},
+		{path: filepath.Join(dir, "deploy", "Dockerfile"), expected: "deploy/Dockerfile"},
+		{path: "/etc/get-version/Dockerfile", expected: "etc/get-version/Dockerfile"},
+	}
+
+	for _, tcase := range tcases {
+		t.Run(tcase.path, func(t *testing.T) {
+			expected := "--- a/" + tcase.expected + "\n+++ b/" + tcase.expected + "\n"
+			if got := UnifiedDiff(tcase.path, []byte("6.1.3\n"), []byte("6.2.1\n")); !strings.HasPrefix(got, expected) {
+				t.Errorf("expected diff to start with:\n%s\ngot:\n%s", expected, got)
+			}
+		})
+	}
+}
+
+func TestUpdate(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "Dockerfile")
+	content := "FROM golang:1.25 AS build\nFROM scylladb/scylla:6.1.3\n"
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+	p, err := Find(path, Dockerfile{Image: "scylladb/scylla"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	_, after, err := Update(p, "6.2.1", true)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := "FROM golang:1.25 AS build\nFROM scylladb/scylla:6.2.1\n"
+	if string(after) != expected {
+		t.Errorf("expected %q, got %q", expected, after)
+	}
+	if written, _ := os.ReadFile(path); string(written) != content {
+		t.Errorf("dry run changed the file: %q", written)
+	}
+
+	if _, _, err = Update(p, "6.2.1", false); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if written, _ := os.ReadFile(path); string(written) != expected {
+		t.Errorf("expected file %q, got %q", expected, written)
+	}
+	if info, _ := os.Stat(path); info.Mode().Perm() != 0o600 {
+		t.Errorf("file mode changed to %s", info.Mode())
+	}
+
+	if _, _, err = Update(p, "6.3.0", false); err == nil {
+		t.Errorf("expected error for changed pin")
+	}
+}
@@ -0,0 +1,127 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/scylladb-actions/get-version/filters"
+	"github.com/scylladb-actions/get-version/sources"
+	"github.com/scylladb-actions/get-version/types"
+	"github.com/scylladb-actions/get-version/version"
+)
+
+// queryRun is a query on its way from params to output. Queries of --config are fetched concurrently
+// and then finished one by one, so that their outputs do not interleave.
+// Sets of --config have no source, they combine versions selected by their inputs.
+type queryRun struct {
+	name   string
+	p      types.Params
+	filter filters.Filter
+	source types.Source
+	inputs []*queryRun
+	write  func(version.Versions) (int, error)
+
+	all      version.Versions
+	ignored  []types.IgnoredVersion
+	selected version.Versions
+	err      error
+}
+
+func newQueryRun(query types.Query) (*queryRun, error) {
+	run := &queryRun{name: query.Name, p: query.Params}
+	filter, err := filters.ParseFilterString(run.p.FiltersDefinition)
+	if err != nil {
+		return nil, run.wrap(err)
+	}
+	run.filter = filter
+
+	// Versions of a release branch are not necessarily the newest ones
+	if run.p.EarlyStop && run.p.Branch == "" {
+		run.p.NewestVersions = filters.NewestNeeded(filter)
+	}
+
+	if run.p.Combine == "" {
+		if run.source, err = sources.AllSources.GetSource(run.p); err != nil {
+			return nil, run.wrap(err)
+		}
+	}
+	if run.write, err = newWriter(run.p); err != nil {
+		return nil, run.wrap(err)
+	}
+	return run, nil
+}
+
+// wrap prefixes error with name of the query, queries given by flags have no name
+func (r *queryRun) wrap(err error) error {
+	if r.name == "" {
+		return err
+	}
+	return fmt.Errorf("%s: %w", r.name, err)
+}
+
+// fetch gets versions from the source and selects them by filters
+func (r *queryRun) fetch() {
+	ctx, cancel := newContext(r.p)
+	defer cancel()
+
+	r.all, r.ignored, r.err = r.source.GetAllVersions(ctx)
+	if r.err != nil {
+		if ctx.Err() != nil {
+			r.err = fmt.Errorf("%w: %w", context.Cause(ctx), r.err)
+		}
+		return
+	}
+	r.selected = r.filter.Apply(r.all)
+}
+
+// combine selects versions of a set from versions selected by its inputs, inputs should be done by then
+func (r *queryRun) combine() {
+	others := make([]version.Versions, 0, len(r.inputs)-1)
+	for _, input := range r.inputs {
+		if input.err != nil {
+			r.err = fmt.Errorf("query %q failed", input.name)
+			return
+		}
+		if input != r.inputs[0] {
+			others = append(others, input.selected)
+		}
+	}
+	r.all = r.p.Combine.Apply(r.inputs[0].selected, others)
+	r.selected = r.filter.Apply(r.all)
+}
+
+// finish writes selected versions and reports them to GitHub Actions, it returns exit code of the query
+func (r *queryRun) finish() int {
+	if r.err != nil {
+		fmt.Fprintln(os.Stderr, r.wrap(r.err).Error())
+		return exitSourceError
+	}
+	// Sets combine all selected versions of their queries, latest command narrows only the output
+	if newest, ok := r.selected.Newest(); ok && r.p.Command == types.CommandLatest {
+		r.selected = version.Versions{newest}
+	}
+	// Outputs of queries of --config are told apart by a header, unless they go to named action outputs
+	if r.name != "" && !r.p.OutAsAction {
+		fmt.Fprintf(os.Stdout, "# %s\n", r.name)
+	}
+
+	exitCode, err := r.write(r.selected)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, r.wrap(err).Error())
+		if exitCode == 0 {
+			exitCode = exitSourceError
+		}
+		return exitCode
+	}
+
+	countErr := r.p.CheckCount(len(r.selected))
+	if r.p.OutAsAction {
+		reportToAction(r.p, r.all, r.ignored, r.selected, countErr)
+	}
+	if countErr != nil {
+		fmt.Fprintln(os.Stderr, r.wrap(countErr).Error())
+		return exitNoMatch
+	}
+	return exitCode
+}
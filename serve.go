@@ -0,0 +1,56 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/scylladb-actions/get-version/server"
+	"github.com/scylladb-actions/get-version/types"
+)
+
+// shutdownTimeout is how long serve waits for queries in flight on SIGINT or SIGTERM
+const shutdownTimeout = 30 * time.Second
+
+// serve answers queries over HTTP until SIGINT or SIGTERM, --timeout limits every query
+func serve(p types.Params) int {
+	srv, err := server.New(p)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err.Error())
+		return exitParseError
+	}
+	listener, err := net.Listen("tcp", p.Listen)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err.Error())
+		return exitSourceError
+	}
+	httpServer := &http.Server{Handler: srv.Handler(), ReadHeaderTimeout: 10 * time.Second}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	errs := make(chan error, 1)
+	go func() {
+		errs <- httpServer.Serve(listener)
+	}()
+	fmt.Fprintf(os.Stderr, "serving on %s\n", listener.Addr())
+
+	select {
+	case err = <-errs:
+		fmt.Fprintln(os.Stderr, err.Error())
+		return exitSourceError
+	case <-ctx.Done():
+	}
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	if err = httpServer.Shutdown(shutdownCtx); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		fmt.Fprintln(os.Stderr, "failed to shut down:", err.Error())
+		return exitSourceError
+	}
+	return 0
+}
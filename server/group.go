@@ -0,0 +1,46 @@
+package server
+
+import (
+	"sync"
+)
+
+// call is a query in flight, requests with the same query wait for its answer instead of running it again
+type call struct {
+	done   chan struct{}
+	answer answer
+}
+
+// group collapses identical queries that run at the same time into one
+type group struct {
+	mu    sync.Mutex
+	calls map[string]*call
+	// joined is called when a request waits for a call in flight
+	joined func()
+}
+
+func newGroup(joined func()) *group {
+	return &group{calls: map[string]*call{}, joined: joined}
+}
+
+// do returns answer of fn, or of the call of fn with the same key that is in flight
+func (g *group) do(key string, fn func() answer) answer {
+	g.mu.Lock()
+	if c, ok := g.calls[key]; ok {
+		g.mu.Unlock()
+		g.joined()
+		<-c.done
+		return c.answer
+	}
+	c := &call{done: make(chan struct{})}
+	g.calls[key] = c
+	g.mu.Unlock()
+
+	defer func() {
+		g.mu.Lock()
+		delete(g.calls, key)
+		g.mu.Unlock()
+		close(c.done)
+	}()
+	c.answer = fn()
+	return c.answer
+}
@@ -0,0 +1,108 @@
+package server
+
+import (
+	"cmp"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/scylladb-actions/get-version/types"
+)
+
+// requestKey labels requests, source is empty for requests with invalid parameters
+type requestKey struct {
+	source types.SourceName
+	code   int
+}
+
+type duration struct {
+	seconds float64
+	count   int
+}
+
+// metrics of queries in Prometheus text format, sources are validated before they are used as labels
+type metrics struct {
+	mu          sync.Mutex
+	inFlight    int
+	requests    map[requestKey]int
+	durations   map[types.SourceName]duration
+	rateLimited map[types.SourceName]int
+	shared      int
+}
+
+func newMetrics() *metrics {
+	return &metrics{
+		requests:    map[requestKey]int{},
+		durations:   map[types.SourceName]duration{},
+		rateLimited: map[types.SourceName]int{},
+	}
+}
+
+func (m *metrics) started() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.inFlight++
+}
+
+func (m *metrics) finished(source types.SourceName, code int, elapsed time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.inFlight--
+	m.requests[requestKey{source: source, code: code}]++
+	d := m.durations[source]
+	d.seconds += elapsed.Seconds()
+	d.count++
+	m.durations[source] = d
+}
+
+func (m *metrics) rateLimitedBy(source types.SourceName) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.rateLimited[source]++
+}
+
+func (m *metrics) sharedQuery() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.shared++
+}
+
+func (m *metrics) write(w io.Writer) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	_, _ = fmt.Fprintln(w, "# HELP get_version_requests_total Queries answered, by source and status code.")
+	_, _ = fmt.Fprintln(w, "# TYPE get_version_requests_total counter")
+	keys := slices.SortedFunc(maps.Keys(m.requests), func(a, b requestKey) int {
+		return cmp.Or(cmp.Compare(a.source, b.source), cmp.Compare(a.code, b.code))
+	})
+	for _, key := range keys {
+		_, _ = fmt.Fprintf(w, "get_version_requests_total{source=%q,code=\"%d\"} %d\n",
+			key.source, key.code, m.requests[key])
+	}
+
+	_, _ = fmt.Fprintln(w, "# HELP get_version_request_duration_seconds Time spent answering queries, by source.")
+	_, _ = fmt.Fprintln(w, "# TYPE get_version_request_duration_seconds summary")
+	for _, source := range slices.Sorted(maps.Keys(m.durations)) {
+		d := m.durations[source]
+		_, _ = fmt.Fprintf(w, "get_version_request_duration_seconds_sum{source=%q} %g\n", source, d.seconds)
+		_, _ = fmt.Fprintf(w, "get_version_request_duration_seconds_count{source=%q} %d\n", source, d.count)
+	}
+
+	_, _ = fmt.Fprintln(w, "# HELP get_version_rate_limited_total Queries that failed on rate limit of the source.")
+	_, _ = fmt.Fprintln(w, "# TYPE get_version_rate_limited_total counter")
+	for _, source := range slices.Sorted(maps.Keys(m.rateLimited)) {
+		_, _ = fmt.Fprintf(w, "get_version_rate_limited_total{source=%q} %d\n", source, m.rateLimited[source])
+	}
+
+	_, _ = fmt.Fprintln(w, "# HELP get_version_shared_queries_total Queries answered by an identical query in flight.")
+	_, _ = fmt.Fprintln(w, "# TYPE get_version_shared_queries_total counter")
+	_, _ = fmt.Fprintf(w, "get_version_shared_queries_total %d\n", m.shared)
+
+	_, _ = fmt.Fprintln(w, "# HELP get_version_requests_in_flight Queries being answered.")
+	_, _ = fmt.Fprintln(w, "# TYPE get_version_requests_in_flight gauge")
+	_, _ = fmt.Fprintf(w, "get_version_requests_in_flight %d\n", m.inFlight)
+}
@@ -0,0 +1,235 @@
+// Package server answers queries of versions over HTTP, the parameters of a query are given by the request
+// and the rest of params, like credentials, cache and retries, are settings of the server.
+package server
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/scylladb-actions/get-version/filters"
+	"github.com/scylladb-actions/get-version/httpclient"
+	"github.com/scylladb-actions/get-version/output"
+	"github.com/scylladb-actions/get-version/sources"
+	"github.com/scylladb-actions/get-version/types"
+)
+
+// queryKeys set params of a query from parameters of a request, URLs of sources are settings of the server,
+// so that requests can't make it reach other hosts
+var queryKeys = map[string]func(p *types.Params, value string) error{
+	"source": func(p *types.Params, value string) error {
+		p.SourceName = types.SourceName(value)
+		return nil
+	},
+	"repo": func(p *types.Params, value string) error {
+		p.Repo = value
+		return nil
+	},
+	"filters": func(p *types.Params, value string) error {
+		p.FiltersDefinition = value
+		return nil
+	},
+	"prefix": func(p *types.Params, value string) error {
+		p.Prefix = value
+		return nil
+	},
+	"mvn-group": func(p *types.Params, value string) error {
+		p.MavenGroup = value
+		return nil
+	},
+	"mvn-artifact-id": func(p *types.Params, value string) error {
+		p.MavenArtifactID = value
+		return nil
+	},
+	"format": func(p *types.Params, value string) error {
+		p.OutFormat = types.OutputName(value)
+		return nil
+	},
+	"no-prefix": func(p *types.Params, value string) (err error) {
+		p.OutNoPrefix, err = strconv.ParseBool(value)
+		return err
+	},
+	"reverse-order": func(p *types.Params, value string) (err error) {
+		p.OutReverseOrder, err = strconv.ParseBool(value)
+		return err
+	},
+}
+
+// contentTypes are content types of formats a query can be answered in, template reads files, so it is not served
+var contentTypes = map[types.OutputName]string{
+	types.OutputTEXT:   "text/plain; charset=utf-8",
+	types.OutputJSON:   "application/json",
+	types.OutputYAML:   "application/yaml",
+	types.OutputMatrix: "application/json",
+}
+
+// Server answers queries with one http client, so that they share its response cache, connections and
+// handling of rate limits instead of spending the rate limit of every caller.
+// Credentials, like GitHub App installation tokens, are kept by source and repo and shared by queries too.
+// A rate limited host fails queries right away until the limit is reset and identical queries in flight are run once.
+type Server struct {
+	params  types.Params
+	metrics *metrics
+	queries *group
+}
+
+// New returns a server for params that are settings of all queries, responses are always cached.
+func New(p types.Params) (*Server, error) {
+	p.Cache = true
+	p.RateLimits = types.NewRateLimits()
+	client, err := httpclient.New(p)
+	if err != nil {
+		return nil, err
+	}
+	p.HTTPClient = client
+	p.Credentials = types.NewCredentials()
+	m := newMetrics()
+	return &Server{params: p, metrics: m, queries: newGroup(m.sharedQuery)}, nil
+}
+
+// Handler returns handler of /v1/versions, /healthz and /metrics in Prometheus text format.
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /v1/versions", s.versions)
+	mux.HandleFunc("GET /healthz", func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte("ok\n"))
+	})
+	mux.HandleFunc("GET /metrics", func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		s.metrics.write(w)
+	})
+	return mux
+}
+
+func (s *Server) versions(w http.ResponseWriter, r *http.Request) {
+	start := time.Now()
+	s.metrics.started()
+	code, sourceName := s.serveVersions(w, r)
+	s.metrics.finished(sourceName, code, time.Since(start))
+}
+
+// answer of a query, it is shared by requests with the same query
+type answer struct {
+	code        int
+	contentType string
+	retryAfter  time.Duration
+	body        []byte
+}
+
+// serveVersions answers a query, it returns the status code and the source for metrics,
+// the source is empty when it is not known
+func (s *Server) serveVersions(w http.ResponseWriter, r *http.Request) (int, types.SourceName) {
+	values := r.URL.Query()
+	p, err := s.queryParams(values)
+	if err != nil {
+		return httpError(w, http.StatusBadRequest, err), ""
+	}
+	filter, err := filters.ParseFilterString(p.FiltersDefinition)
+	if err != nil {
+		return httpError(w, http.StatusBadRequest, err), p.SourceName
+	}
+	// The query runs for every request waiting for it, so that one of them leaving does not cancel it
+	a := s.queries.do(values.Encode(), func() answer {
+		return s.query(context.WithoutCancel(r.Context()), p, filter)
+	})
+	if a.code != http.StatusOK {
+		if a.retryAfter > 0 {
+			w.Header().Set("Retry-After", strconv.Itoa(int(a.retryAfter.Round(time.Second).Seconds())))
+		}
+		return httpError(w, a.code, errors.New(string(a.body))), p.SourceName
+	}
+	w.Header().Set("Content-Type", a.contentType)
+	_, _ = w.Write(a.body)
+	return a.code, p.SourceName
+}
+
+// query gets versions of a query from its source, the answer is an error for codes other than 200
+func (s *Server) query(ctx context.Context, p types.Params, filter filters.Filter) answer {
+	if p.EarlyStop {
+		p.NewestVersions = filters.NewestNeeded(filter)
+	}
+	source, err := sources.AllSources.GetSource(p)
+	if err != nil {
+		return errorAnswer(http.StatusBadRequest, err)
+	}
+	format := &bytes.Buffer{}
+	o, err := output.NewFormat(p, format)
+	if err != nil {
+		return errorAnswer(http.StatusBadRequest, err)
+	}
+
+	if p.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeoutCause(ctx, p.Timeout, fmt.Errorf("timed out after %s", p.Timeout))
+		defer cancel()
+	}
+	all, _, err := source.GetAllVersions(ctx)
+	var rateLimitErr *httpclient.RateLimitError
+	switch {
+	case errors.As(err, &rateLimitErr):
+		s.metrics.rateLimitedBy(p.SourceName)
+		a := errorAnswer(http.StatusServiceUnavailable, err)
+		a.retryAfter = max(time.Until(rateLimitErr.Until), time.Second)
+		return a
+	case errors.Is(err, httpclient.ErrRateLimited):
+		s.metrics.rateLimitedBy(p.SourceName)
+		return errorAnswer(http.StatusServiceUnavailable, err)
+	case err != nil && ctx.Err() != nil:
+		return errorAnswer(http.StatusGatewayTimeout, fmt.Errorf("%w: %w", context.Cause(ctx), err))
+	case err != nil:
+		return errorAnswer(http.StatusBadGateway, err)
+	}
+	if err = o.Write(filter.Apply(all)); err != nil {
+		return errorAnswer(http.StatusInternalServerError, err)
+	}
+	return answer{code: http.StatusOK, contentType: contentTypes[p.OutFormat], body: format.Bytes()}
+}
+
+func errorAnswer(code int, err error) answer {
+	return answer{code: code, body: []byte(err.Error())}
+}
+
+// queryParams returns params of the server with the query of the request on top of them
+func (s *Server) queryParams(values url.Values) (types.Params, error) {
+	p := s.params
+	p.Command = types.CommandList
+	p.OutFormat = types.OutputJSON
+	for key, value := range values {
+		set, ok := queryKeys[key]
+		if !ok {
+			return types.Params{}, fmt.Errorf("unknown parameter %q", key)
+		}
+		if len(value) != 1 {
+			return types.Params{}, fmt.Errorf("parameter %q should be given once", key)
+		}
+		if err := set(&p, value[0]); err != nil {
+			return types.Params{}, fmt.Errorf("invalid %s: %w", key, err)
+		}
+	}
+	isSet := func(name string) bool {
+		return values.Get(name) != ""
+	}
+	if err := types.ValidateSourceFlags(p.SourceName, isSet); err != nil {
+		return types.Params{}, err
+	}
+	if p.SourceName == types.File || p.SourceName == types.Stdin {
+		return types.Params{}, fmt.Errorf("source %s reads local files, it is not served", p.SourceName)
+	}
+	if _, ok := contentTypes[p.OutFormat]; !ok {
+		return types.Params{}, fmt.Errorf("format %q is not served", p.OutFormat)
+	}
+	if err := p.Complete(sources.AllSources); err != nil {
+		return types.Params{}, err
+	}
+	return p, nil
+}
+
+func httpError(w http.ResponseWriter, code int, err error) int {
+	http.Error(w, err.Error(), code)
+	return code
+}
@@ -0,0 +1,310 @@
+package server
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/scylladb-actions/get-version/sources/maven/maventest"
+	"github.com/scylladb-actions/get-version/types"
+)
+
+func newTestServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	maven := maventest.NewServer()
+	t.Cleanup(maven.Close)
+
+	p := types.DefaultParams()
+	p.Command = types.CommandServe
+	p.MavenURL = maven.URL
+	p.CacheDir = t.TempDir()
+	p.RetryMax = 0
+	_, server := startServer(t, p)
+	return server
+}
+
+func startServer(t *testing.T, p types.Params) (*Server, *httptest.Server) {
+	t.Helper()
+	srv, err := New(p)
+	if err != nil {
+		t.Fatalf("failed to create server: %v", err)
+	}
+	server := httptest.NewServer(srv.Handler())
+	t.Cleanup(server.Close)
+	return srv, server
+}
+
+// gitHubParams returns settings of a server that gets GitHub tags from the stub at url with a token
+func gitHubParams(t *testing.T, url string) types.Params {
+	t.Helper()
+	p := types.DefaultParams()
+	p.Command = types.CommandServe
+	p.CacheDir = t.TempDir()
+	p.RetryMax = 0
+	p.GitHubAPI = types.GitHubAPIREST
+	p.GitHubAPIURL = url
+	p.GitHubToken = "test-token"
+	return p
+}
+
+func get(t *testing.T, server *httptest.Server, path string) (int, string, string) {
+	t.Helper()
+	resp, err := http.Get(server.URL + path)
+	if err != nil {
+		t.Fatalf("failed to get %s: %v", path, err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("failed to read %s: %v", path, err)
+	}
+	return resp.StatusCode, resp.Header.Get("Content-Type"), string(body)
+}
+
+func TestServeVersions(t *testing.T) {
+	server := newTestServer(t)
+	maven := "/v1/versions?source=maven-artifact&mvn-group=" + maventest.Group + "&mvn-artifact-id=" + maventest.ArtifactID
+
+	tcases := []struct {
+		name        string
+		path        string
+		code        int
+		contentType string
+		body        string
+	}{
+		{
+			name:        "json by default",
+			path:        maven + "&filters=LAST.*.*",
+			code:        http.StatusOK,
+			contentType: "application/json",
+			body:        `["4.17.0.1","4.18.0.0"]`,
+		},
+		{
+			name:        "text",
+			path:        maven + "&filters=LAST.LAST.LAST&format=text",
+			code:        http.StatusOK,
+			contentType: "text/plain; charset=utf-8",
+			body:        "4.18.0.0",
+		},
+		{
+			name: "unknown parameter",
+			path: maven + "&mvn-url=http://localhost",
+			code: http.StatusBadRequest,
+			body: `unknown parameter "mvn-url"`,
+		},
+		{
+			name: "repeated parameter",
+			path: maven + "&filters=LAST.*.*&filters=FIRST.*.*",
+			code: http.StatusBadRequest,
+			body: `parameter "filters" should be given once`,
+		},
+		{
+			name: "flag of other source",
+			path: "/v1/versions?source=github-tag&repo=scylladb/scylla&mvn-group=com.scylladb",
+			code: http.StatusBadRequest,
+			body: "does not apply to source github-tag",
+		},
+		{
+			name: "missing required flag",
+			path: "/v1/versions?source=github-tag",
+			code: http.StatusBadRequest,
+			body: "--repo",
+		},
+		{
+			name: "local file",
+			path: "/v1/versions?source=stdin",
+			code: http.StatusBadRequest,
+			body: "it is not served",
+		},
+		{
+			name: "template format",
+			path: maven + "&format=template",
+			code: http.StatusBadRequest,
+			body: `format "template" is not served`,
+		},
+		{
+			name: "invalid filter",
+			path: maven + "&filters=LAST.*",
+			code: http.StatusBadRequest,
+		},
+		{
+			name: "source error",
+			path: "/v1/versions?source=maven-artifact&mvn-group=" + maventest.Group + "&mvn-artifact-id=unknown",
+			code: http.StatusBadGateway,
+		},
+	}
+
+	for _, tcase := range tcases {
+		t.Run(tcase.name, func(t *testing.T) {
+			code, contentType, body := get(t, server, tcase.path)
+			if code != tcase.code {
+				t.Fatalf("expected code %d, got %d: %s", tcase.code, code, body)
+			}
+			if tcase.contentType != "" && contentType != tcase.contentType {
+				t.Errorf("expected content type %q, got %q", tcase.contentType, contentType)
+			}
+			if !strings.Contains(body, tcase.body) {
+				t.Errorf("expected body to contain %q, got %q", tcase.body, body)
+			}
+		})
+	}
+}
+
+func TestServeHealthAndMetrics(t *testing.T) {
+	server := newTestServer(t)
+	get(t, server,
+		"/v1/versions?source=maven-artifact&mvn-group="+maventest.Group+"&mvn-artifact-id="+maventest.ArtifactID)
+	get(t, server, "/v1/versions?source=unknown")
+
+	if code, _, body := get(t, server, "/healthz"); code != http.StatusOK || body != "ok\n" {
+		t.Errorf("unexpected health response %d %q", code, body)
+	}
+	code, _, body := get(t, server, "/metrics")
+	if code != http.StatusOK {
+		t.Fatalf("unexpected metrics response %d", code)
+	}
+	for _, expected := range []string{
+		`get_version_requests_total{source="",code="400"} 1`,
+		`get_version_requests_total{source="maven-artifact",code="200"} 1`,
+		`get_version_request_duration_seconds_count{source="maven-artifact"} 1`,
+		"get_version_requests_in_flight 0",
+	} {
+		if !strings.Contains(body, expected) {
+			t.Errorf("expected metrics to contain %q, got:\n%s", expected, body)
+		}
+	}
+}
+
+func TestServeSharesGitHubAppToken(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+
+	var issued atomic.Int32
+	github := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/repos/scylladb/scylla/installation":
+			_, _ = w.Write([]byte(`{"id":42}`))
+		case r.Method == http.MethodPost && r.URL.Path == "/app/installations/42/access_tokens":
+			w.WriteHeader(http.StatusCreated)
+			_, _ = fmt.Fprintf(w, `{"token":"token-%d","expires_at":%q}`,
+				issued.Add(1), time.Now().Add(time.Hour).Format(time.RFC3339))
+		case r.Method == http.MethodGet && r.URL.Path == "/repos/scylladb/scylla/tags":
+			_, _ = w.Write([]byte(`[{"name":"6.2.0"},{"name":"6.1.3"}]`))
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer github.Close()
+
+	p := gitHubParams(t, github.URL)
+	p.GitHubToken = ""
+	p.GitHubAppID = "12345"
+	p.GitHubAppPrivateKey = string(keyPEM)
+	_, server := startServer(t, p)
+
+	for _, filters := range []string{"LAST.*.*", "*.*.*"} {
+		code, _, body := get(t, server, "/v1/versions?source=github-tag&repo=scylladb/scylla&filters="+filters)
+		if code != http.StatusOK {
+			t.Fatalf("expected status 200, got %d: %s", code, body)
+		}
+	}
+	if got := issued.Load(); got != 1 {
+		t.Errorf("expected one installation token for both requests, got %d", got)
+	}
+}
+
+func TestServeRateLimited(t *testing.T) {
+	var hits atomic.Int32
+	reset := time.Now().Add(time.Hour)
+	github := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		hits.Add(1)
+		w.Header().Set("X-RateLimit-Remaining", "0")
+		w.Header().Set("X-RateLimit-Reset", strconv.FormatInt(reset.Unix(), 10))
+		http.Error(w, "API rate limit exceeded", http.StatusForbidden)
+	}))
+	defer github.Close()
+	_, server := startServer(t, gitHubParams(t, github.URL))
+
+	for _, repo := range []string{"scylladb/scylla", "scylladb/gocql"} {
+		resp, err := http.Get(server.URL + "/v1/versions?source=github-tag&repo=" + repo)
+		if err != nil {
+			t.Fatalf("failed to get versions: %v", err)
+		}
+		_ = resp.Body.Close()
+		if resp.StatusCode != http.StatusServiceUnavailable {
+			t.Errorf("%s: expected status 503, got %d", repo, resp.StatusCode)
+		}
+		retryAfter, err := strconv.Atoi(resp.Header.Get("Retry-After"))
+		if err != nil || retryAfter < 3500 || retryAfter > 3600 {
+			t.Errorf("%s: expected Retry-After until the reset, got %q", repo, resp.Header.Get("Retry-After"))
+		}
+	}
+	// The second query fails without reaching the rate limited host
+	if got := hits.Load(); got != 1 {
+		t.Errorf("expected one request to GitHub, got %d", got)
+	}
+}
+
+func TestServeSharesIdenticalQueries(t *testing.T) {
+	const requests = 5
+	var hits atomic.Int32
+	release := make(chan struct{})
+	github := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		hits.Add(1)
+		<-release
+		_, _ = w.Write([]byte(`[{"name":"6.2.0"},{"name":"6.1.3"}]`))
+	}))
+	defer github.Close()
+	// Released on failures too, so that closing the stub does not wait for the held query
+	releaseQuery := sync.OnceFunc(func() { close(release) })
+	defer releaseQuery()
+	srv, server := startServer(t, gitHubParams(t, github.URL))
+
+	bodies := make(chan string, requests)
+	for range requests {
+		go func() {
+			code, _, body := get(t, server, "/v1/versions?source=github-tag&repo=scylladb/scylla")
+			if code != http.StatusOK {
+				t.Errorf("expected status 200, got %d: %s", code, body)
+			}
+			bodies <- body
+		}()
+	}
+	// The query is held by the stub until all other requests wait for it
+	for deadline := time.Now().Add(5 * time.Second); ; time.Sleep(time.Millisecond) {
+		srv.metrics.mu.Lock()
+		shared := srv.metrics.shared
+		srv.metrics.mu.Unlock()
+		if shared == requests-1 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("expected %d requests to wait for the query, got %d", requests-1, shared)
+		}
+	}
+	releaseQuery()
+
+	for range requests {
+		if body := <-bodies; body != "[\"6.1.3\",\"6.2.0\"]\n" {
+			t.Errorf("unexpected body %q", body)
+		}
+	}
+	if got := hits.Load(); got != 1 {
+		t.Errorf("expected one request to GitHub, got %d", got)
+	}
+}
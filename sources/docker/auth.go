@@ -2,6 +2,7 @@ package docker
 
 import (
 	"bytes"
+	"context"
 	"encoding/base64"
 	"encoding/json"
 	"errors"
@@ -10,8 +11,12 @@ import (
 	"net/http"
 	"os"
 	"strings"
+	"sync"
+	"time"
 
 	cliconfig "github.com/docker/cli/cli/config"
+
+	"github.com/scylladb-actions/get-version/ghaction"
 )
 
 const (
@@ -19,7 +24,7 @@ const (
 	dockerEnvConfigKey     = "DOCKER_AUTH_CONFIG"
 )
 
-var dockerHubAuthTokenURL = "https://hub.docker.com/v2/auth/token"
+var dockerHubAuthTokenURL = "%s/v2/auth/token"
 
 type envAuthConfig struct {
 	Auth string `json:"auth"`
@@ -34,16 +39,70 @@ type dockerBasicAuth struct {
 	Password string
 }
 
-func getDockerHubAuthToken(cl *http.Client) (string, error) {
+// authTokenRefreshMargin makes sure that the token does not expire in the middle of a request
+const authTokenRefreshMargin = time.Minute
+
+// authTokenSource resolves Docker CLI credentials to a Docker Hub token and keeps it until it expires,
+// tokens without expiration time are resolved for every query.
+type authTokenSource struct {
+	cl     *http.Client
+	apiURL string
+	now    func() time.Time
+	log    io.Writer
+
+	mu        sync.Mutex
+	token     string
+	expiresAt time.Time
+}
+
+func newAuthTokenSource(cl *http.Client, apiURL string, log io.Writer) *authTokenSource {
+	return &authTokenSource{cl: cl, apiURL: apiURL, now: time.Now, log: log}
+}
+
+func (s *authTokenSource) Token(ctx context.Context) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.token != "" && s.now().Add(authTokenRefreshMargin).Before(s.expiresAt) {
+		return s.token, nil
+	}
+	token, err := getDockerHubAuthToken(ctx, s.cl, s.apiURL, s.log)
+	if err != nil {
+		return "", err
+	}
+	s.token, s.expiresAt = token, tokenExpiration(token)
+	return token, nil
+}
+
+// tokenExpiration returns exp claim of a JWT, zero time for other tokens
+func tokenExpiration(token string) time.Time {
+	chunks := strings.Split(token, ".")
+	if len(chunks) != 3 {
+		return time.Time{}
+	}
+	rawClaims, err := base64.RawURLEncoding.DecodeString(chunks[1])
+	if err != nil {
+		return time.Time{}
+	}
+	var claims struct {
+		Exp int64 `json:"exp"`
+	}
+	if err = json.Unmarshal(rawClaims, &claims); err != nil || claims.Exp == 0 {
+		return time.Time{}
+	}
+	return time.Unix(claims.Exp, 0)
+}
+
+// getDockerHubAuthToken resolves Docker CLI credentials to a Docker Hub token, warnings are written to log
+func getDockerHubAuthToken(ctx context.Context, cl *http.Client, apiURL string, log io.Writer) (string, error) {
 	cfg := cliconfig.LoadDefaultConfigFile(io.Discard)
 	envAuthConfigs, envErr := parseDockerAuthConfigFromEnv()
 	if envErr != nil {
-		_, _ = fmt.Fprintln(os.Stderr, "Failed to create credential store from DOCKER_AUTH_CONFIG: ", envErr)
+		_, _ = fmt.Fprintln(log, "Failed to create credential store from DOCKER_AUTH_CONFIG: ", envErr)
 	}
 	var firstErr error
 
 	if envAuth, ok := envAuthConfigs[dockerHubAuthConfigKey]; ok {
-		return createDockerHubAccessToken(cl, envAuth.Username, envAuth.Password)
+		return createDockerHubAccessToken(ctx, cl, log, apiURL, envAuth.Username, envAuth.Password)
 	}
 
 	authCfg, err := cfg.GetAuthConfig(dockerHubAuthConfigKey)
@@ -63,9 +122,11 @@ func getDockerHubAuthToken(cl *http.Client) (string, error) {
 	}
 
 	if authCfg.RegistryToken != "" {
+		ghaction.Mask(log, authCfg.RegistryToken)
 		return authCfg.RegistryToken, nil
 	}
 	if authCfg.IdentityToken != "" {
+		ghaction.Mask(log, authCfg.IdentityToken)
 		return authCfg.IdentityToken, nil
 	}
 	if authCfg.Username == "" && authCfg.Password == "" && authCfg.Auth != "" {
@@ -79,7 +140,7 @@ func getDockerHubAuthToken(cl *http.Client) (string, error) {
 	if authCfg.Username == "" || authCfg.Password == "" {
 		return "", fmt.Errorf("docker credentials for Docker Hub are missing username or password")
 	}
-	return createDockerHubAccessToken(cl, authCfg.Username, authCfg.Password)
+	return createDockerHubAccessToken(ctx, cl, log, apiURL, authCfg.Username, authCfg.Password)
 }
 
 func parseDockerAuthConfigFromEnv() (map[string]dockerBasicAuth, error) {
@@ -129,7 +190,12 @@ func decodeDockerAuth(authValue string) (string, string, error) {
 	return username, strings.Trim(password, "\x00"), nil
 }
 
-func createDockerHubAccessToken(cl *http.Client, username, secret string) (string, error) {
+func createDockerHubAccessToken(
+	ctx context.Context,
+	cl *http.Client,
+	log io.Writer,
+	apiURL, username, secret string,
+) (string, error) {
 	body, err := json.Marshal(struct {
 		Identifier string `json:"identifier"`
 		Secret     string `json:"secret"`
@@ -140,7 +206,8 @@ func createDockerHubAccessToken(cl *http.Client, username, secret string) (strin
 	if err != nil {
 		return "", err
 	}
-	rq, err := http.NewRequest(http.MethodPost, dockerHubAuthTokenURL, bytes.NewReader(body))
+	tokenURL := fmt.Sprintf(dockerHubAuthTokenURL, getDockerHubURL(apiURL))
+	rq, err := http.NewRequestWithContext(ctx, http.MethodPost, tokenURL, bytes.NewReader(body))
 	if err != nil {
 		return "", err
 	}
@@ -169,5 +236,6 @@ func createDockerHubAccessToken(cl *http.Client, username, secret string) (strin
 	if responseBody.AccessToken == "" {
 		return "", fmt.Errorf("failed to request Docker Hub auth token: empty access_token in response")
 	}
+	ghaction.Mask(log, responseBody.AccessToken)
 	return responseBody.AccessToken, nil
 }
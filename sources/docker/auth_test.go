@@ -1,13 +1,17 @@
 package docker
 
 import (
+	"context"
 	"encoding/base64"
 	"encoding/json"
+	"fmt"
+	"io"
 	"net/http"
 	"net/http/httptest"
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 
 	cliconfig "github.com/docker/cli/cli/config"
 )
@@ -59,6 +63,9 @@ func TestGetDockerHubAuthToken_UsesDockerAuthConfigEnv(t *testing.T) {
 		if r.Method != http.MethodPost {
 			t.Fatalf("expected method %s, got %s", http.MethodPost, r.Method)
 		}
+		if r.URL.Path != "/v2/auth/token" {
+			t.Fatalf("unexpected path %q", r.URL.Path)
+		}
 		var payload struct {
 			Identifier string `json:"identifier"`
 			Secret     string `json:"secret"`
@@ -76,13 +83,7 @@ func TestGetDockerHubAuthToken_UsesDockerAuthConfigEnv(t *testing.T) {
 	}))
 	defer server.Close()
 
-	originalTokenURL := dockerHubAuthTokenURL
-	dockerHubAuthTokenURL = server.URL
-	t.Cleanup(func() {
-		dockerHubAuthTokenURL = originalTokenURL
-	})
-
-	token, err := getDockerHubAuthToken(server.Client())
+	token, err := getDockerHubAuthToken(context.Background(), server.Client(), server.URL, io.Discard)
 	if err != nil {
 		t.Fatalf("getDockerHubAuthToken failed: %v", err)
 	}
@@ -102,7 +103,7 @@ func TestGetDockerHubAuthToken_UsesRegistryTokenFromConfig(t *testing.T) {
 
 	writeDockerConfigFile(t, configDir, `{"auths":{"`+dockerHubAuthConfigKey+`":{"registrytoken":"registry-token"}}}`)
 
-	token, err := getDockerHubAuthToken(http.DefaultClient)
+	token, err := getDockerHubAuthToken(context.Background(), http.DefaultClient, "", io.Discard)
 	if err != nil {
 		t.Fatalf("getDockerHubAuthToken failed: %v", err)
 	}
@@ -118,3 +119,53 @@ func writeDockerConfigFile(t *testing.T, configDir, data string) {
 		t.Fatalf("failed to write docker config file: %v", err)
 	}
 }
+
+func TestAuthTokenSource(t *testing.T) {
+	configDir := t.TempDir()
+	originalConfigDir := cliconfig.Dir()
+	cliconfig.SetDir(configDir)
+	t.Cleanup(func() {
+		cliconfig.SetDir(originalConfigDir)
+	})
+	writeDockerConfigFile(t, configDir, `{"auths":{}}`)
+	auth := base64.StdEncoding.EncodeToString([]byte("docker-user:docker-pass"))
+	t.Setenv(dockerEnvConfigKey, `{"auths":{"`+dockerHubAuthConfigKey+`":{"auth":"`+auth+`"}}}`)
+
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	claims := base64.RawURLEncoding.EncodeToString([]byte(fmt.Sprintf(`{"exp":%d}`, now.Add(time.Hour).Unix())))
+	jwt := "header." + claims + ".signature"
+	issued := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		issued++
+		_, _ = fmt.Fprintf(w, `{"access_token":%q}`, jwt)
+	}))
+	defer server.Close()
+
+	ts := newAuthTokenSource(server.Client(), server.URL, io.Discard)
+	ts.now = func() time.Time { return now }
+	for range 2 {
+		token, err := ts.Token(context.Background())
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if token != jwt {
+			t.Fatalf("unexpected token %q", token)
+		}
+	}
+	if issued != 1 {
+		t.Fatalf("expected token to be reused, issued %d", issued)
+	}
+
+	// Token is refreshed close to its expiry
+	ts.now = func() time.Time { return now.Add(59 * time.Minute) }
+	if _, err := ts.Token(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if issued != 2 {
+		t.Fatalf("expected token to be refreshed, issued %d", issued)
+	}
+
+	if expiresAt := tokenExpiration("env-token"); !expiresAt.IsZero() {
+		t.Fatalf("expected no expiration for token that is not a JWT, got %s", expiresAt)
+	}
+}
@@ -1,6 +1,7 @@
 package docker
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
@@ -11,41 +12,53 @@ import (
 	"github.com/scylladb-actions/get-version/version"
 )
 
+// dockerImageTagOrdering lists tags from the most recently pushed, it is the default ordering of Docker Hub
+// and it is set explicitly when listing stops after the newest tags
+const dockerImageTagOrdering = "&ordering=last_updated"
+
 var (
-	dockerImageTagNamespacedURL = "https://hub.docker.com/v2/namespaces/%s/repositories/%s/tags?page_size=1000"
-	dockerImageTagURL           = "https://hub.docker.com/v2/repositories/library/%s/tags?page_size=1000"
+	dockerImageTagNamespacedURL = "%s/v2/namespaces/%s/repositories/%s/tags?page_size=1000"
+	dockerImageTagURL           = "%s/v2/repositories/library/%s/tags?page_size=1000"
 )
 
-func getDockerURLFromRepo(repo string) string {
+func getDockerHubURL(apiURL string) string {
+	if apiURL == "" {
+		return types.DefaultDockerHubURL
+	}
+	return strings.TrimSuffix(apiURL, "/")
+}
+
+func getDockerURLFromRepo(apiURL, repo string) string {
 	chunks := strings.SplitN(repo, "/", 2)
 	if len(chunks) == 2 {
-		return fmt.Sprintf(dockerImageTagNamespacedURL, chunks[0], chunks[1])
+		return fmt.Sprintf(dockerImageTagNamespacedURL, getDockerHubURL(apiURL), chunks[0], chunks[1])
 	}
-	return fmt.Sprintf(dockerImageTagURL, repo)
+	return fmt.Sprintf(dockerImageTagURL, getDockerHubURL(apiURL), repo)
 }
 
 func getDockerImageVersionsOnce(
+	ctx context.Context,
 	cl *http.Client,
 	url, prefix, authToken string,
-) (out version.Versions, ignored []types.IgnoredVersion, next string, statusCode int, err error) {
+) (page httpclient.Page[httpclient.VersionsPage], statusCode int, err error) {
 	var rq *http.Request
-	rq, err = http.NewRequest(http.MethodGet, url, nil)
+	rq, err = http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
 	if err != nil {
-		return nil, nil, "", 0, err
+		return page, 0, err
 	}
 	if authToken != "" {
 		rq.Header.Set("Authorization", "Bearer "+authToken)
 	}
 	resp, err := cl.Do(rq)
 	if err != nil {
-		return nil, nil, "", 0,
+		return page, 0,
 			fmt.Errorf("failed to execute http GET request for url %q: %w", url, err)
 	}
 	defer func() {
 		_ = resp.Body.Close()
 	}()
 	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
-		return nil, nil, "", resp.StatusCode,
+		return page, resp.StatusCode,
 			fmt.Errorf("failed to execute http GET request for url %q, server replied with %s", url, resp.Status)
 	}
 
@@ -54,6 +67,7 @@ func getDockerImageVersionsOnce(
 	}
 
 	var body struct {
+		Count   int
 		Next    string
 		Results []Tag
 	}
@@ -61,9 +75,11 @@ func getDockerImageVersionsOnce(
 	dec := json.NewDecoder(resp.Body)
 	err = dec.Decode(&body)
 	if err != nil {
-		return nil, nil, "", resp.StatusCode, fmt.Errorf("failed to parse server response: %w", err)
+		return page, resp.StatusCode, fmt.Errorf("failed to parse server response: %w", err)
 	}
 
+	var out version.Versions
+	var ignored []types.IgnoredVersion
 	for _, rec := range body.Results {
 		if prefix != "" && !strings.HasPrefix(rec.Name, prefix) {
 			ignored = append(ignored, types.IgnoredVersion{
@@ -82,41 +98,51 @@ func getDockerImageVersionsOnce(
 		ver.SetPrefix(prefix)
 		out = append(out, ver)
 	}
-	return out, ignored, body.Next, resp.StatusCode, nil
+
+	page = httpclient.Page[httpclient.VersionsPage]{
+		Value: httpclient.VersionsPage{Versions: out, Ignored: ignored},
+		Next:  body.Next,
+	}
+	// Docker Hub may serve less tags per page than requested, so page size is taken from the full page
+	if body.Next != "" && len(body.Results) > 0 {
+		page.LastPage = (body.Count + len(body.Results) - 1) / len(body.Results)
+	}
+	return page, resp.StatusCode, nil
 }
 
 type Source struct {
 	params types.Params
 }
 
-func (s Source) GetAllVersions() (out version.Versions, ignored []types.IgnoredVersion, err error) {
-	cl := httpclient.New(s.params)
-	authToken, authTokenErr := getDockerHubAuthToken(cl)
-	url := getDockerURLFromRepo(s.params.Repo)
-	for url != "" {
-		for retry := 0; ; retry++ {
-			versions, ignoredVersions, nextURL, statusCode, err := getDockerImageVersionsOnce(
-				cl,
-				url,
-				s.params.Prefix,
-				authToken,
-			)
-			if err != nil {
-				if authTokenErr != nil && (statusCode == http.StatusForbidden || statusCode == http.StatusUnauthorized) {
-					return nil, nil, fmt.Errorf("%w; failed to resolve Docker CLI credentials: %v", err, authTokenErr)
-				}
-				if retry > 5 {
-					return nil, nil, fmt.Errorf("failed to execute query to %s, last error: %w", url, err)
-				}
-				continue
+func (s Source) GetAllVersions(ctx context.Context) (out version.Versions, ignored []types.IgnoredVersion, err error) {
+	cl, err := httpclient.New(s.params)
+	if err != nil {
+		return nil, nil, err
+	}
+	tokens, _ := types.SharedCredentials(s.params, func() (*authTokenSource, error) {
+		return newAuthTokenSource(cl, s.params.DockerHubURL, s.params.LogOutput()), nil
+	})
+	authToken, authTokenErr := tokens.Token(ctx)
+	url := getDockerURLFromRepo(s.params.DockerHubURL, s.params.Repo)
+	if s.params.NewestVersions > 0 {
+		url += dockerImageTagOrdering
+	}
+	pages, err := httpclient.Paginate(ctx, url, s.params.Concurrency,
+		func(ctx context.Context, url string) (httpclient.Page[httpclient.VersionsPage], error) {
+			page, statusCode, err := getDockerImageVersionsOnce(ctx, cl, url, s.params.Prefix, authToken)
+			if err != nil && authTokenErr != nil &&
+				(statusCode == http.StatusForbidden || statusCode == http.StatusUnauthorized) {
+				return page, fmt.Errorf("%w; failed to resolve Docker CLI credentials: %v", err, authTokenErr)
 			}
-			out = append(out, versions...)
-			ignored = append(ignored, ignoredVersions...)
-			url = nextURL
-			break
-		}
+			return page, err
+		}, httpclient.StopAfter(s.params.NewestVersions))
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, page := range pages {
+		out = append(out, page.Versions...)
+		ignored = append(ignored, page.Ignored...)
 	}
-
 	return out, ignored, nil
 }
 
@@ -0,0 +1,84 @@
+package docker
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+
+	cliconfig "github.com/docker/cli/cli/config"
+
+	"github.com/scylladb-actions/get-version/types"
+)
+
+func TestSourceGetAllVersions(t *testing.T) {
+	originalConfigDir := cliconfig.Dir()
+	cliconfig.SetDir(t.TempDir())
+	t.Cleanup(func() {
+		cliconfig.SetDir(originalConfigDir)
+	})
+	t.Setenv(dockerEnvConfigKey, "")
+
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v2/namespaces/scylladb/repositories/scylla/tags" {
+			t.Errorf("unexpected path %q", r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		switch r.URL.Query().Get("page") {
+		case "":
+			_, _ = fmt.Fprintf(w, `{"count":4,"next":"%s%s?page=2&page_size=2","results":[`+
+				`{"name":"6.2.1"},{"name":"latest"}]}`, server.URL, r.URL.Path)
+		case "2":
+			_, _ = w.Write([]byte(`{"count":4,"next":"","results":[{"name":"6.2.0"},{"name":"6.1.5"}]}`))
+		default:
+			t.Errorf("unexpected page %q", r.URL.Query().Get("page"))
+		}
+	}))
+	defer server.Close()
+
+	source, err := New(types.Params{Repo: "scylladb/scylla", DockerHubURL: server.URL, Concurrency: 4})
+	if err != nil {
+		t.Fatalf("failed to create source: %v", err)
+	}
+	versions, ignored, err := source.GetAllVersions(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := []string{"6.2.1", "6.2.0", "6.1.5"}
+	if got := versions.AsStringSlice(true); !slices.Equal(got, expected) {
+		t.Fatalf("expected versions %v, got %v", expected, got)
+	}
+	if len(ignored) != 1 || ignored[0].Version != "latest" {
+		t.Fatalf("unexpected ignored versions %v", ignored)
+	}
+}
+
+func TestDockerURLs(t *testing.T) {
+	tcases := []struct {
+		apiURL   string
+		repo     string
+		expected string
+	}{
+		{
+			repo:     "ubuntu",
+			expected: "https://hub.docker.com/v2/repositories/library/ubuntu/tags?page_size=1000",
+		},
+		{
+			apiURL:   "https://nexus.internal/dockerhub/",
+			repo:     "scylladb/scylla",
+			expected: "https://nexus.internal/dockerhub/v2/namespaces/scylladb/repositories/scylla/tags?page_size=1000",
+		},
+	}
+
+	for _, tcase := range tcases {
+		t.Run(tcase.repo, func(t *testing.T) {
+			if got := getDockerURLFromRepo(tcase.apiURL, tcase.repo); got != tcase.expected {
+				t.Fatalf("expected %q, got %q", tcase.expected, got)
+			}
+		})
+	}
+}
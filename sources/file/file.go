@@ -0,0 +1,110 @@
+package file
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/scylladb-actions/get-version/types"
+	"github.com/scylladb-actions/get-version/version"
+)
+
+// Stdin is the --file value that reads versions from standard input.
+const Stdin = "-"
+
+// Source reads versions from a file or from standard input, network is not accessed.
+// Content is a version per line, or a JSON or YAML list of versions.
+type Source struct {
+	params types.Params
+	stdin  io.Reader
+}
+
+func (s Source) GetAllVersions(ctx context.Context) (version.Versions, []types.IgnoredVersion, error) {
+	content, err := s.read()
+	if err != nil {
+		return nil, nil, err
+	}
+	if err = ctx.Err(); err != nil {
+		return nil, nil, err
+	}
+	names, err := parseNames(content)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to parse versions of %s: %w", s.name(), err)
+	}
+
+	var out version.Versions
+	var ignored []types.IgnoredVersion
+	for _, name := range names {
+		ver, ignoredVersion := types.NewVersion(name, s.params.Prefix)
+		if ignoredVersion != nil {
+			ignored = append(ignored, *ignoredVersion)
+			continue
+		}
+		out = append(out, ver)
+	}
+	return out, ignored, nil
+}
+
+func (s Source) read() ([]byte, error) {
+	if s.params.File == Stdin {
+		content, err := io.ReadAll(s.stdin)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read standard input: %w", err)
+		}
+		return content, nil
+	}
+	content, err := os.ReadFile(s.params.File)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read versions: %w", err)
+	}
+	return content, nil
+}
+
+func (s Source) name() string {
+	if s.params.File == Stdin {
+		return "standard input"
+	}
+	return s.params.File
+}
+
+// parseNames returns versions of a JSON or YAML list, which starts with [ or -, or versions of lines otherwise.
+// Empty lines and lines that start with # are skipped.
+func parseNames(content []byte) ([]string, error) {
+	trimmed := bytes.TrimSpace(content)
+	if bytes.HasPrefix(trimmed, []byte("[")) || bytes.HasPrefix(trimmed, []byte("- ")) ||
+		bytes.HasPrefix(trimmed, []byte("---")) {
+		var names []string
+		if err := yaml.Unmarshal(trimmed, &names); err != nil {
+			return nil, err
+		}
+		return names, nil
+	}
+
+	var names []string
+	for line := range strings.Lines(string(trimmed)) {
+		line = strings.TrimSpace(line)
+		if line != "" && !strings.HasPrefix(line, "#") {
+			names = append(names, line)
+		}
+	}
+	return names, nil
+}
+
+// New returns a source that reads --file, - reads standard input.
+func New(p types.Params) (Source, error) {
+	if p.File == "" {
+		return Source{}, fmt.Errorf("file is empty")
+	}
+	return Source{params: p, stdin: os.Stdin}, nil
+}
+
+// NewStdin returns a source that reads standard input.
+func NewStdin(p types.Params) Source {
+	p.File = Stdin
+	return Source{params: p, stdin: os.Stdin}
+}
@@ -0,0 +1,81 @@
+package file
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/scylladb-actions/get-version/types"
+)
+
+func TestParseNames(t *testing.T) {
+	tcases := []struct {
+		name     string
+		content  string
+		expected []string
+	}{
+		{name: "lines", content: "v6.2.1\n\n# comment\n  v6.1.0  \r\nv6.0.0", expected: []string{"v6.2.1", "v6.1.0", "v6.0.0"}},
+		{name: "json", content: ` ["6.2.1", "6.1.0"]`, expected: []string{"6.2.1", "6.1.0"}},
+		{name: "yaml", content: "- 6.2.1\n- 6.10\n", expected: []string{"6.2.1", "6.10"}},
+		{name: "yaml document", content: "---\n- 6.2.1\n", expected: []string{"6.2.1"}},
+		{name: "empty", content: "\n"},
+	}
+
+	for _, tcase := range tcases {
+		t.Run(tcase.name, func(t *testing.T) {
+			got, err := parseNames([]byte(tcase.content))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !slices.Equal(got, tcase.expected) {
+				t.Errorf("expected %q, got %q", tcase.expected, got)
+			}
+		})
+	}
+
+	if _, err := parseNames([]byte(`["6.2.1", {"v": 1}]`)); err == nil {
+		t.Errorf("expected error for list of objects")
+	}
+}
+
+func TestSourceGetAllVersions(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tags")
+	if err := os.WriteFile(path, []byte("v6.2.1\nv6.1.0\n6.0.0\nvnext\n"), 0o644); err != nil {
+		t.Fatalf("failed to write versions: %v", err)
+	}
+	source, err := New(types.Params{File: path, Prefix: "v"})
+	if err != nil {
+		t.Fatalf("failed to create source: %v", err)
+	}
+
+	versions, ignored, err := source.GetAllVersions(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := []string{"v6.2.1", "v6.1.0"}
+	if got := versions.AsStringSlice(true); !slices.Equal(got, expected) {
+		t.Errorf("expected versions %v, got %v", expected, got)
+	}
+	if len(ignored) != 2 || ignored[0].Version != "6.0.0" || ignored[1].Version != "next" {
+		t.Errorf("unexpected ignored versions %v", ignored)
+	}
+}
+
+func TestSourceStdin(t *testing.T) {
+	source := NewStdin(types.Params{})
+	source.stdin = strings.NewReader(`["6.2.1", "6.1.0"]`)
+	versions, _, err := source.GetAllVersions(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := versions.AsStringSlice(true); !slices.Equal(got, []string{"6.2.1", "6.1.0"}) {
+		t.Errorf("unexpected versions %v", got)
+	}
+
+	if _, err = New(types.Params{}); err == nil {
+		t.Errorf("expected error without file")
+	}
+}
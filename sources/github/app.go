@@ -0,0 +1,203 @@
+package github
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/scylladb-actions/get-version/ghaction"
+	"github.com/scylladb-actions/get-version/types"
+)
+
+const (
+	// appJWTLifetime is kept below 10 minutes, which is the maximum GitHub accepts
+	appJWTLifetime = 9 * time.Minute
+	// appTokenRefreshMargin makes sure that installation token does not expire in the middle of a request
+	appTokenRefreshMargin = time.Minute
+)
+
+// tokenSource provides a token for every GitHub API request.
+type tokenSource interface {
+	Token(ctx context.Context) (string, error)
+}
+
+type staticToken string
+
+func (t staticToken) Token(context.Context) (string, error) {
+	return string(t), nil
+}
+
+// appTokenSource authenticates as GitHub App installation,
+// it mints installation tokens on demand and refreshes them before they expire.
+type appTokenSource struct {
+	cl             *http.Client
+	apiURL         string
+	appID          string
+	key            *rsa.PrivateKey
+	installationID int64
+	repo           string
+	now            func() time.Time
+	log            io.Writer
+
+	mu        sync.Mutex
+	token     string
+	expiresAt time.Time
+}
+
+func newTokenSource(cl *http.Client, params types.Params) (tokenSource, error) {
+	if !params.UseGitHubApp() {
+		return staticToken(params.GitHubToken), nil
+	}
+	pemData := []byte(params.GitHubAppPrivateKey)
+	if params.GitHubAppPrivateKeyFile != "" {
+		var err error
+		pemData, err = os.ReadFile(params.GitHubAppPrivateKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read GitHub App private key: %w", err)
+		}
+	}
+	key, err := parseAppPrivateKey(pemData)
+	if err != nil {
+		return nil, err
+	}
+	return &appTokenSource{
+		cl:             cl,
+		apiURL:         getGitHubAPIURL(params.GitHubAPIURL),
+		appID:          params.GitHubAppID,
+		key:            key,
+		installationID: params.GitHubAppInstallationID,
+		repo:           params.Repo,
+		now:            time.Now,
+		log:            params.LogOutput(),
+	}, nil
+}
+
+func parseAppPrivateKey(pemData []byte) (*rsa.PrivateKey, error) {
+	block, _ := pem.Decode(pemData)
+	if block == nil {
+		return nil, fmt.Errorf("failed to decode GitHub App private key: no PEM data found")
+	}
+	// GitHub issues PKCS#1 keys, PKCS#8 is accepted for keys converted by other tools
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse GitHub App private key: %w", err)
+	}
+	rsaKey, ok := key.(*rsa.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("GitHub App private key should be RSA key, got %T", key)
+	}
+	return rsaKey, nil
+}
+
+func (s *appTokenSource) Token(ctx context.Context) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.token != "" && s.now().Add(appTokenRefreshMargin).Before(s.expiresAt) {
+		return s.token, nil
+	}
+
+	jwt, err := s.jwt()
+	if err != nil {
+		return "", err
+	}
+	ghaction.Mask(s.log, jwt)
+	if s.installationID == 0 {
+		s.installationID, err = s.lookupInstallation(ctx, jwt)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	var body struct {
+		Token     string    `json:"token"`
+		ExpiresAt time.Time `json:"expires_at"`
+	}
+	url := fmt.Sprintf("%s/app/installations/%d/access_tokens", s.apiURL, s.installationID)
+	if err = s.do(ctx, http.MethodPost, url, jwt, &body); err != nil {
+		return "", fmt.Errorf("failed to create GitHub App installation token: %w", err)
+	}
+	if body.Token == "" {
+		return "", fmt.Errorf("failed to create GitHub App installation token: empty token in response")
+	}
+	ghaction.Mask(s.log, body.Token)
+	s.token, s.expiresAt = body.Token, body.ExpiresAt
+	return s.token, nil
+}
+
+func (s *appTokenSource) lookupInstallation(ctx context.Context, jwt string) (int64, error) {
+	owner, name, err := splitRepo(s.repo)
+	if err != nil {
+		return 0, fmt.Errorf("failed to find GitHub App installation, provide installation ID or %w", err)
+	}
+	var body struct {
+		ID int64 `json:"id"`
+	}
+	url := fmt.Sprintf("%s/repos/%s/%s/installation", s.apiURL, owner, name)
+	if err = s.do(ctx, http.MethodGet, url, jwt, &body); err != nil {
+		return 0, fmt.Errorf("failed to find GitHub App installation for repo %q: %w", s.repo, err)
+	}
+	return body.ID, nil
+}
+
+func (s *appTokenSource) do(ctx context.Context, method, url, jwt string, out any) error {
+	rq, err := http.NewRequestWithContext(ctx, method, url, nil)
+	if err != nil {
+		return err
+	}
+	rq.Header.Set("Accept", "application/vnd.github+json")
+	rq.Header.Set("Authorization", "Bearer "+jwt)
+	resp, err := s.cl.Do(rq)
+	if err != nil {
+		return fmt.Errorf("failed to execute http %s request for url %q: %w", method, url, err)
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		return fmt.Errorf("server replied with %s for url %q", resp.Status, url)
+	}
+	if err = json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to parse server response: %w", err)
+	}
+	return nil
+}
+
+// jwt creates a JSON Web Token signed by app private key, which is used to call GitHub App endpoints
+func (s *appTokenSource) jwt() (string, error) {
+	now := s.now()
+	header, err := json.Marshal(map[string]string{"alg": "RS256", "typ": "JWT"})
+	if err != nil {
+		return "", err
+	}
+	claims, err := json.Marshal(map[string]any{
+		// Issued a minute in the past to tolerate clock drift
+		"iat": now.Add(-time.Minute).Unix(),
+		"exp": now.Add(appJWTLifetime).Unix(),
+		"iss": s.appID,
+	})
+	if err != nil {
+		return "", err
+	}
+	unsigned := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(claims)
+	digest := sha256.Sum256([]byte(unsigned))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, s.key, crypto.SHA256, digest[:])
+	if err != nil {
+		return "", fmt.Errorf("failed to sign GitHub App JWT: %w", err)
+	}
+	return unsigned + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
@@ -0,0 +1,130 @@
+package github
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/scylladb-actions/get-version/types"
+)
+
+func verifyAppJWT(t *testing.T, key *rsa.PrivateKey, authHeader string) {
+	t.Helper()
+	jwt, ok := strings.CutPrefix(authHeader, "Bearer ")
+	if !ok {
+		t.Fatalf("expected bearer token, got %q", authHeader)
+	}
+	chunks := strings.Split(jwt, ".")
+	if len(chunks) != 3 {
+		t.Fatalf("expected JWT with 3 parts, got %q", jwt)
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(chunks[2])
+	if err != nil {
+		t.Fatalf("failed to decode JWT signature: %v", err)
+	}
+	digest := sha256.Sum256([]byte(chunks[0] + "." + chunks[1]))
+	if err = rsa.VerifyPKCS1v15(&key.PublicKey, crypto.SHA256, digest[:], signature); err != nil {
+		t.Fatalf("invalid JWT signature: %v", err)
+	}
+	rawClaims, err := base64.RawURLEncoding.DecodeString(chunks[1])
+	if err != nil {
+		t.Fatalf("failed to decode JWT claims: %v", err)
+	}
+	var claims struct {
+		Iss string `json:"iss"`
+	}
+	if err = json.Unmarshal(rawClaims, &claims); err != nil {
+		t.Fatalf("failed to parse JWT claims: %v", err)
+	}
+	if claims.Iss != "12345" {
+		t.Fatalf("expected iss %q, got %q", "12345", claims.Iss)
+	}
+}
+
+func TestAppTokenSource(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	issued := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		verifyAppJWT(t, key, r.Header.Get("Authorization"))
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/repos/scylladb/scylla/installation":
+			_, _ = w.Write([]byte(`{"id":42}`))
+		case r.Method == http.MethodPost && r.URL.Path == "/app/installations/42/access_tokens":
+			issued++
+			w.WriteHeader(http.StatusCreated)
+			_, _ = fmt.Fprintf(w, `{"token":"token-%d","expires_at":%q}`,
+				issued, now.Add(time.Hour).Format(time.RFC3339))
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	ts, err := newTokenSource(server.Client(), types.Params{
+		Repo:                "scylladb/scylla",
+		GitHubAPIURL:        server.URL,
+		GitHubAppID:         "12345",
+		GitHubAppPrivateKey: string(keyPEM),
+	})
+	if err != nil {
+		t.Fatalf("failed to create token source: %v", err)
+	}
+	appTS := ts.(*appTokenSource)
+	appTS.now = func() time.Time { return now }
+
+	for _, expected := range []string{"token-1", "token-1"} {
+		token, err := ts.Token(context.Background())
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if token != expected {
+			t.Fatalf("expected token %q, got %q", expected, token)
+		}
+	}
+
+	// Token is refreshed when pagination runs close to its expiry
+	appTS.now = func() time.Time { return now.Add(59 * time.Minute) }
+	token, err := ts.Token(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if token != "token-2" {
+		t.Fatalf("expected refreshed token %q, got %q", "token-2", token)
+	}
+}
+
+func TestNewTokenSourceStatic(t *testing.T) {
+	ts, err := newTokenSource(http.DefaultClient, types.Params{GitHubToken: "test-token"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	token, err := ts.Token(context.Background())
+	if err != nil || token != "test-token" {
+		t.Fatalf("expected token %q, got %q, %v", "test-token", token, err)
+	}
+}
+
+func TestParseAppPrivateKeyInvalid(t *testing.T) {
+	if _, err := parseAppPrivateKey([]byte("not a key")); err == nil {
+		t.Fatalf("expected error for invalid key")
+	}
+}
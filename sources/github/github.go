@@ -1,63 +1,79 @@
 package github
 
 import (
+	"context"
 	"encoding/json"
-	"errors"
 	"fmt"
 	"net/http"
 	"strings"
-	"time"
 
 	"github.com/scylladb-actions/get-version/httpclient"
 	"github.com/scylladb-actions/get-version/types"
 	"github.com/scylladb-actions/get-version/version"
 )
 
-// ErrRateLimited is returned when GitHub API returns 403 rate limit exceeded
-var ErrRateLimited = errors.New("rate limit exceeded")
+// ErrRateLimited is returned when GitHub API keeps replying with rate limit errors after all retries
+var ErrRateLimited = httpclient.ErrRateLimited
 
 var (
-	githubReleaseURL = "https://api.github.com/repos/%s/releases?per_page=100"
-	githubTagURL     = "https://api.github.com/repos/%s/tags?per_page=100"
+	githubReleaseURL = "%s/repos/%s/releases?per_page=100"
+	githubTagURL     = "%s/repos/%s/tags?per_page=100"
 )
 
 type versionExtractor func(r *http.Response) (version.Versions, []types.IgnoredVersion, error)
 
-func getGitHubReleaseURL(repo string) string {
-	return fmt.Sprintf(githubReleaseURL, repo)
+func getGitHubAPIURL(apiURL string) string {
+	if apiURL == "" {
+		return types.DefaultGitHubAPIURL
+	}
+	return strings.TrimSuffix(apiURL, "/")
+}
+
+func getGitHubReleaseURL(apiURL, repo string) string {
+	return fmt.Sprintf(githubReleaseURL, getGitHubAPIURL(apiURL), repo)
 }
 
-func getGitHubTagURL(repo string) string {
-	return fmt.Sprintf(githubTagURL, repo)
+func getGitHubTagURL(apiURL, repo string) string {
+	return fmt.Sprintf(githubTagURL, getGitHubAPIURL(apiURL), repo)
 }
 
-func getNextLink(resp *http.Response) string {
+// getGitHubGraphQLURL returns GraphQL endpoint for the API base URL:
+// https://api.github.com/graphql for github.com and https://<host>/api/graphql for GitHub Enterprise Server,
+// which serves REST API on https://<host>/api/v3.
+func getGitHubGraphQLURL(apiURL string) string {
+	apiURL = getGitHubAPIURL(apiURL)
+	if base, ok := strings.CutSuffix(apiURL, "/v3"); ok {
+		return base + "/graphql"
+	}
+	return apiURL + "/graphql"
+}
+
+// getLink returns URL of the link with given relation from Link header, like rel="next" or rel="last"
+func getLink(resp *http.Response, rel string) string {
 	links := resp.Header.Get("link")
-	nextLink := ""
 	for _, link := range strings.Split(links, ",") {
 		chunks := strings.SplitN(link, ";", 2)
 		if len(chunks) != 2 {
 			continue
 		}
 		linkInfo, rev := chunks[0], chunks[1]
-		if strings.Contains(rev, "rel=\"next\"") {
-			nextLink = strings.Trim(linkInfo, "<> ")
-			break
+		if strings.Contains(rev, "rel=\""+rel+"\"") {
+			return strings.Trim(linkInfo, "<> ")
 		}
 	}
-	return nextLink
+	return ""
 }
 
 func executeQuery(
+	ctx context.Context,
 	cl *http.Client,
 	url string,
 	token string,
 	extractor versionExtractor,
-) (out version.Versions, ignored []types.IgnoredVersion, next string, err error) {
-	var rq *http.Request
-	rq, err = http.NewRequest(http.MethodGet, url, nil)
+) (httpclient.Page[httpclient.VersionsPage], error) {
+	rq, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
 	if err != nil {
-		return nil, nil, "", err
+		return httpclient.Page[httpclient.VersionsPage]{}, err
 	}
 	rq.Header.Set("Accept", "application/vnd.github+json")
 	if token != "" {
@@ -65,22 +81,29 @@ func executeQuery(
 	}
 	resp, err := cl.Do(rq)
 	if err != nil {
-		return nil, nil, "",
+		return httpclient.Page[httpclient.VersionsPage]{},
 			fmt.Errorf("failed to execute http GET request for url %q: %w", url, err)
 	}
-	if resp.StatusCode == http.StatusForbidden {
-		return nil, nil, "",
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+	if httpclient.IsRateLimited(resp) {
+		return httpclient.Page[httpclient.VersionsPage]{},
 			fmt.Errorf("%w: server replied with %s for url %q", ErrRateLimited, resp.Status, url)
 	}
 	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
-		return nil, nil, "",
+		return httpclient.Page[httpclient.VersionsPage]{},
 			fmt.Errorf("failed to execute http GET request for url %q, server replied with %s", url, resp.Status)
 	}
-	out, ignored, err = extractor(resp)
+	out, ignored, err := extractor(resp)
 	if err != nil {
-		return nil, nil, "", err
+		return httpclient.Page[httpclient.VersionsPage]{}, err
 	}
-	return out, ignored, getNextLink(resp), nil
+	return httpclient.Page[httpclient.VersionsPage]{
+		Value:    httpclient.VersionsPage{Versions: out, Ignored: ignored},
+		Next:     getLink(resp, "next"),
+		LastPage: httpclient.PageNumber(getLink(resp, "last")),
+	}, nil
 }
 
 func extractVersionsFromRelease(resp *http.Response, prefix string) (version.Versions, []types.IgnoredVersion, error) {
@@ -102,55 +125,46 @@ func extractVersionsFromRelease(resp *http.Response, prefix string) (version.Ver
 		if rec.Draft {
 			continue
 		}
-		name := rec.Name
-		if prefix != "" && !strings.HasPrefix(name, prefix) {
-			ignored = append(ignored,
-				types.IgnoredVersion{
-					Version: name,
-					Reason:  fmt.Errorf("version %q does not have prefix %q", name, prefix),
-				})
-			continue
-		}
-		name = strings.TrimPrefix(name, prefix)
-		ver, err := version.New(name)
-		if err != nil {
-			ignored = append(ignored, types.IgnoredVersion{Version: name, Reason: err})
+		ver, ignoredVersion := types.NewVersion(rec.Name, prefix)
+		if ignoredVersion != nil {
+			ignored = append(ignored, *ignoredVersion)
 			continue
 		}
-		ver.SetPrefix(prefix)
 		out = append(out, ver)
 	}
 	return out, ignored, nil
 }
 
+// getVersionsFromGitHub lists all pages starting from url, if the API lists newest versions first
+// newest tells how many of them are enough, 0 to list all versions.
 func getVersionsFromGitHub(
+	ctx context.Context,
 	cl *http.Client,
 	url string,
 	extractor versionExtractor,
 	params types.Params,
+	newest int,
 ) (out version.Versions, ignored []types.IgnoredVersion, err error) {
-	for url != "" {
-		for retry := 0; ; retry++ {
-			versions, ignoredVersions, nextURL, queryErr := executeQuery(cl, url, params.GitHubToken, extractor)
-			if queryErr != nil {
-				if retry >= params.RetryMax {
-					return nil, nil, fmt.Errorf("failed to execute query to %s, last error: %w", url, queryErr)
-				}
-				// Apply exponential backoff for rate limit errors
-				if errors.Is(queryErr, ErrRateLimited) {
-					delay := params.RetryInitialDelay * (1 << retry) // 2^retry * initial delay
-					if delay > params.RetryMaxDelay {
-						delay = params.RetryMaxDelay
-					}
-					time.Sleep(time.Duration(delay) * time.Millisecond)
-				}
-				continue
+	tokens, err := types.SharedCredentials(params, func() (tokenSource, error) {
+		return newTokenSource(cl, params)
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	pages, err := httpclient.Paginate(ctx, url, params.Concurrency,
+		func(ctx context.Context, url string) (httpclient.Page[httpclient.VersionsPage], error) {
+			token, err := tokens.Token(ctx)
+			if err != nil {
+				return httpclient.Page[httpclient.VersionsPage]{}, err
 			}
-			ignored = append(ignored, ignoredVersions...)
-			out = append(out, versions...)
-			url = nextURL
-			break
-		}
+			return executeQuery(ctx, cl, url, token, extractor)
+		}, httpclient.StopAfter(newest))
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, page := range pages {
+		out = append(out, page.Versions...)
+		ignored = append(ignored, page.Ignored...)
 	}
 	return out, ignored, nil
 }
@@ -159,14 +173,20 @@ type TagSource struct {
 	params types.Params
 }
 
-func (s TagSource) GetAllVersions() (version.Versions, []types.IgnoredVersion, error) {
+func (s TagSource) GetAllVersions(ctx context.Context) (version.Versions, []types.IgnoredVersion, error) {
+	cl, err := httpclient.New(s.params)
+	if err != nil {
+		return nil, nil, err
+	}
 	return getVersionsFromGitHub(
-		httpclient.New(s.params),
-		getGitHubTagURL(s.params.Repo),
+		ctx,
+		cl,
+		getGitHubTagURL(s.params.GitHubAPIURL, s.params.Repo),
 		func(r *http.Response) (version.Versions, []types.IgnoredVersion, error) {
 			return extractVersionsFromRelease(r, s.params.Prefix)
 		},
 		s.params,
+		0,
 	)
 }
 
@@ -178,14 +198,22 @@ type ReleaseSource struct {
 	params types.Params
 }
 
-func (s ReleaseSource) GetAllVersions() (out version.Versions, ignored []types.IgnoredVersion, err error) {
+func (s ReleaseSource) GetAllVersions(
+	ctx context.Context,
+) (out version.Versions, ignored []types.IgnoredVersion, err error) {
+	cl, err := httpclient.New(s.params)
+	if err != nil {
+		return nil, nil, err
+	}
 	return getVersionsFromGitHub(
-		httpclient.New(s.params),
-		getGitHubReleaseURL(s.params.Repo),
+		ctx,
+		cl,
+		getGitHubReleaseURL(s.params.GitHubAPIURL, s.params.Repo),
 		func(r *http.Response) (version.Versions, []types.IgnoredVersion, error) {
 			return extractVersionsFromRelease(r, s.params.Prefix)
 		},
 		s.params,
+		s.params.NewestVersions,
 	)
 }
 
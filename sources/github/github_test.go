@@ -1,6 +1,9 @@
 package github
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -26,12 +29,12 @@ func TestExecuteQueryWithToken(t *testing.T) {
 		return extractVersionsFromRelease(r, "")
 	}
 
-	versions, _, _, err := executeQuery(server.Client(), server.URL, "test-token-123", extractor)
+	page, err := executeQuery(context.Background(), server.Client(), server.URL, "test-token-123", extractor)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
-	if len(versions) != 1 {
-		t.Fatalf("expected 1 version, got %d", len(versions))
+	if len(page.Value.Versions) != 1 {
+		t.Fatalf("expected 1 version, got %d", len(page.Value.Versions))
 	}
 }
 
@@ -52,11 +55,135 @@ func TestExecuteQueryWithoutToken(t *testing.T) {
 		return extractVersionsFromRelease(r, "")
 	}
 
-	versions, _, _, err := executeQuery(server.Client(), server.URL, "", extractor)
+	page, err := executeQuery(context.Background(), server.Client(), server.URL, "", extractor)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
-	if len(versions) != 1 {
-		t.Fatalf("expected 1 version, got %d", len(versions))
+	if len(page.Value.Versions) != 1 {
+		t.Fatalf("expected 1 version, got %d", len(page.Value.Versions))
+	}
+}
+
+func TestExecuteQueryForbidden(t *testing.T) {
+	tcases := []struct {
+		name        string
+		header      map[string]string
+		rateLimited bool
+	}{
+		{name: "permission error"},
+		{name: "rate limit exhausted", header: map[string]string{"X-RateLimit-Remaining": "0"}, rateLimited: true},
+		{name: "retry after", header: map[string]string{"Retry-After": "60"}, rateLimited: true},
+	}
+
+	for _, tcase := range tcases {
+		t.Run(tcase.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				for key, value := range tcase.header {
+					w.Header().Set(key, value)
+				}
+				http.Error(w, `{"message":"Resource not accessible by integration"}`, http.StatusForbidden)
+			}))
+			defer server.Close()
+
+			extractor := func(r *http.Response) (version.Versions, []types.IgnoredVersion, error) {
+				return extractVersionsFromRelease(r, "")
+			}
+			_, err := executeQuery(context.Background(), server.Client(), server.URL, "", extractor)
+			if err == nil {
+				t.Fatalf("expected error")
+			}
+			if errors.Is(err, ErrRateLimited) != tcase.rateLimited {
+				t.Errorf("expected rate limited %v, got %v", tcase.rateLimited, err)
+			}
+		})
+	}
+}
+
+func TestReleaseSourceEarlyStop(t *testing.T) {
+	requested := 0
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requested++
+		page := r.URL.Query().Get("page")
+		if page == "" {
+			page = "1"
+		}
+		if page != "3" {
+			var next int
+			_, _ = fmt.Sscanf(page, "%d", &next)
+			w.Header().Set("Link", fmt.Sprintf(
+				`<%[1]s/repos/scylladb/scylla/releases?per_page=100&page=%[2]d>; rel="next", `+
+					`<%[1]s/repos/scylladb/scylla/releases?per_page=100&page=3>; rel="last"`, server.URL, next+1))
+		}
+		_, _ = fmt.Fprintf(w, `[{"name":"rc-%[1]s"},{"name":"6.%[1]s.0"}]`, page)
+	}))
+	defer server.Close()
+
+	tcases := []struct {
+		name           string
+		newest         int
+		expectVersions int
+		expectRequests int
+	}{
+		{name: "all pages", newest: 0, expectVersions: 3, expectRequests: 3},
+		{name: "newest version", newest: 1, expectVersions: 1, expectRequests: 1},
+		{name: "two newest versions", newest: 2, expectVersions: 2, expectRequests: 2},
+	}
+
+	for _, tcase := range tcases {
+		t.Run(tcase.name, func(t *testing.T) {
+			requested = 0
+			params := types.Params{
+				Repo:           "scylladb/scylla",
+				GitHubAPIURL:   server.URL,
+				Concurrency:    1,
+				NewestVersions: tcase.newest,
+			}
+			versions, _, err := NewReleaseSource(params).GetAllVersions(context.Background())
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(versions) != tcase.expectVersions {
+				t.Fatalf("expected %d versions, got %v", tcase.expectVersions, versions.AsStringSlice(true))
+			}
+			if requested != tcase.expectRequests {
+				t.Fatalf("expected %d requests, got %d", tcase.expectRequests, requested)
+			}
+		})
+	}
+}
+
+func TestGitHubURLs(t *testing.T) {
+	tcases := []struct {
+		apiURL     string
+		tagURL     string
+		graphQLURL string
+	}{
+		{
+			apiURL:     "",
+			tagURL:     "https://api.github.com/repos/scylladb/scylla/tags?per_page=100",
+			graphQLURL: "https://api.github.com/graphql",
+		},
+		{
+			apiURL:     "https://api.github.com/",
+			tagURL:     "https://api.github.com/repos/scylladb/scylla/tags?per_page=100",
+			graphQLURL: "https://api.github.com/graphql",
+		},
+		{
+			apiURL:     "https://github.example.com/api/v3",
+			tagURL:     "https://github.example.com/api/v3/repos/scylladb/scylla/tags?per_page=100",
+			graphQLURL: "https://github.example.com/api/graphql",
+		},
+	}
+
+	for _, tcase := range tcases {
+		t.Run(tcase.apiURL, func(t *testing.T) {
+			if got := getGitHubTagURL(tcase.apiURL, "scylladb/scylla"); got != tcase.tagURL {
+				t.Errorf("expected tag url %q, got %q", tcase.tagURL, got)
+			}
+			if got := getGitHubGraphQLURL(tcase.apiURL); got != tcase.graphQLURL {
+				t.Errorf("expected graphql url %q, got %q", tcase.graphQLURL, got)
+			}
+		})
 	}
 }
@@ -0,0 +1,277 @@
+package github
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/scylladb-actions/get-version/httpclient"
+	"github.com/scylladb-actions/get-version/types"
+	"github.com/scylladb-actions/get-version/version"
+)
+
+const graphQLTagsQuery = `query($owner: String!, $name: String!, $cursor: String) {
+  repository(owner: $owner, name: $name) {
+    items: refs(refPrefix: "refs/tags/", first: 100, after: $cursor) {
+      pageInfo { hasNextPage endCursor }
+      nodes {
+        name
+        target {
+          oid
+          ... on Tag { target { oid } tagger { date } }
+          ... on Commit { committedDate }
+        }
+      }
+    }
+  }
+}`
+
+const graphQLReleasesQuery = `query($owner: String!, $name: String!, $cursor: String) {
+  repository(owner: $owner, name: $name) {
+    items: releases(first: 100, after: $cursor, orderBy: {field: CREATED_AT, direction: DESC}) {
+      pageInfo { hasNextPage endCursor }
+      nodes {
+        name
+        tagName
+        isDraft
+        isPrerelease
+        publishedAt
+        tagCommit { oid }
+      }
+    }
+  }
+}`
+
+type graphQLPageInfo struct {
+	HasNextPage bool
+	EndCursor   string
+}
+
+type graphQLResponse[T any] struct {
+	Data struct {
+		Repository *struct {
+			Items struct {
+				PageInfo graphQLPageInfo
+				Nodes    []T
+			}
+		}
+	}
+	Errors []struct {
+		Type    string
+		Message string
+	}
+}
+
+type graphQLTag struct {
+	Name   string
+	Target struct {
+		OID    string
+		Target struct {
+			OID string
+		}
+		Tagger struct {
+			Date string
+		}
+		CommittedDate string
+	}
+}
+
+func (t graphQLTag) metadata() version.Metadata {
+	// Annotated tags point to a tag object, which in turn points to the commit
+	commit, date := t.Target.OID, t.Target.CommittedDate
+	if t.Target.Target.OID != "" {
+		commit, date = t.Target.Target.OID, t.Target.Tagger.Date
+	}
+	return version.Metadata{"tag": t.Name, "commit": commit, "date": date}
+}
+
+type graphQLRelease struct {
+	Name         string
+	TagName      string
+	IsDraft      bool
+	IsPrerelease bool
+	PublishedAt  string
+	TagCommit    struct {
+		OID string
+	}
+}
+
+func (r graphQLRelease) metadata() version.Metadata {
+	return version.Metadata{
+		"tag":        r.TagName,
+		"commit":     r.TagCommit.OID,
+		"date":       r.PublishedAt,
+		"prerelease": fmt.Sprint(r.IsPrerelease),
+	}
+}
+
+func splitRepo(repo string) (owner, name string, err error) {
+	owner, name, ok := strings.Cut(repo, "/")
+	if !ok || owner == "" || name == "" || strings.Contains(name, "/") {
+		return "", "", fmt.Errorf("repo %q should be in owner/name format", repo)
+	}
+	return owner, name, nil
+}
+
+func executeGraphQLQuery[T any](
+	ctx context.Context,
+	cl *http.Client,
+	url string,
+	token string,
+	query string,
+	variables map[string]any,
+) (nodes []T, pageInfo graphQLPageInfo, err error) {
+	body, err := json.Marshal(map[string]any{"query": query, "variables": variables})
+	if err != nil {
+		return nil, graphQLPageInfo{}, err
+	}
+	rq, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return nil, graphQLPageInfo{}, err
+	}
+	rq.Header.Set("Content-Type", "application/json")
+	rq.Header.Set("Authorization", "Bearer "+token)
+	resp, err := cl.Do(httpclient.MarkCacheable(rq))
+	if err != nil {
+		return nil, graphQLPageInfo{},
+			fmt.Errorf("failed to execute http POST request for url %q: %w", url, err)
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+	if httpclient.IsRateLimited(resp) {
+		return nil, graphQLPageInfo{},
+			fmt.Errorf("%w: server replied with %s for url %q", ErrRateLimited, resp.Status, url)
+	}
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		return nil, graphQLPageInfo{},
+			fmt.Errorf("failed to execute http POST request for url %q, server replied with %s", url, resp.Status)
+	}
+
+	var respBody graphQLResponse[T]
+	if err = json.NewDecoder(resp.Body).Decode(&respBody); err != nil {
+		return nil, graphQLPageInfo{}, fmt.Errorf("failed to parse server response: %w", err)
+	}
+	if len(respBody.Errors) != 0 {
+		if respBody.Errors[0].Type == "RATE_LIMITED" {
+			return nil, graphQLPageInfo{}, fmt.Errorf("%w: %s", httpclient.ErrQueryRateLimited, respBody.Errors[0].Message)
+		}
+		return nil, graphQLPageInfo{}, fmt.Errorf("graphql query failed: %s", respBody.Errors[0].Message)
+	}
+	if respBody.Data.Repository == nil {
+		return nil, graphQLPageInfo{}, fmt.Errorf("repository %s/%s is not found", variables["owner"], variables["name"])
+	}
+	return respBody.Data.Repository.Items.Nodes, respBody.Data.Repository.Items.PageInfo, nil
+}
+
+func getVersionsFromGraphQL[T any](
+	ctx context.Context,
+	cl *http.Client,
+	url string,
+	query string,
+	params types.Params,
+	extractor func(T) (name string, metadata version.Metadata, skip bool),
+	newest int,
+) (out version.Versions, ignored []types.IgnoredVersion, err error) {
+	owner, name, err := splitRepo(params.Repo)
+	if err != nil {
+		return nil, nil, err
+	}
+	tokens, err := types.SharedCredentials(params, func() (tokenSource, error) {
+		return newTokenSource(cl, params)
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	variables := map[string]any{"owner": owner, "name": name, "cursor": nil}
+	for {
+		var nodes []T
+		var pageInfo graphQLPageInfo
+		err = httpclient.NewRetryPolicy(params).Do(ctx, func() error {
+			token, queryErr := tokens.Token(ctx)
+			if queryErr != nil {
+				return queryErr
+			}
+			nodes, pageInfo, queryErr = executeGraphQLQuery[T](ctx, cl, url, token, query, variables)
+			return queryErr
+		})
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, node := range nodes {
+			versionName, metadata, skip := extractor(node)
+			if skip {
+				continue
+			}
+			ver, ignoredVersion := types.NewVersion(versionName, params.Prefix)
+			if ignoredVersion != nil {
+				ignored = append(ignored, *ignoredVersion)
+				continue
+			}
+			ver.SetMetadata(metadata)
+			out = append(out, ver)
+		}
+		if !pageInfo.HasNextPage || (newest > 0 && len(out) >= newest) {
+			return out, ignored, nil
+		}
+		variables["cursor"] = pageInfo.EndCursor
+	}
+}
+
+// GraphQLTagSource lists repository tags through GitHub GraphQL API,
+// it takes 100 tags per request and provides commit metadata for every tag.
+type GraphQLTagSource struct {
+	params types.Params
+}
+
+func (s GraphQLTagSource) GetAllVersions(ctx context.Context) (version.Versions, []types.IgnoredVersion, error) {
+	cl, err := httpclient.New(s.params)
+	if err != nil {
+		return nil, nil, err
+	}
+	return getVersionsFromGraphQL(
+		ctx,
+		cl,
+		getGitHubGraphQLURL(s.params.GitHubAPIURL),
+		graphQLTagsQuery,
+		s.params,
+		func(tag graphQLTag) (string, version.Metadata, bool) {
+			return tag.Name, tag.metadata(), false
+		},
+		0,
+	)
+}
+
+func NewGraphQLTagSource(params types.Params) GraphQLTagSource {
+	return GraphQLTagSource{params: params}
+}
+
+// GraphQLReleaseSource lists repository releases through GitHub GraphQL API.
+type GraphQLReleaseSource struct {
+	params types.Params
+}
+
+func (s GraphQLReleaseSource) GetAllVersions(ctx context.Context) (version.Versions, []types.IgnoredVersion, error) {
+	cl, err := httpclient.New(s.params)
+	if err != nil {
+		return nil, nil, err
+	}
+	return getVersionsFromGraphQL(
+		ctx,
+		cl,
+		getGitHubGraphQLURL(s.params.GitHubAPIURL),
+		graphQLReleasesQuery,
+		s.params,
+		func(release graphQLRelease) (string, version.Metadata, bool) {
+			return release.Name, release.metadata(), release.IsDraft
+		},
+		s.params.NewestVersions,
+	)
+}
+
+func NewGraphQLReleaseSource(params types.Params) GraphQLReleaseSource {
+	return GraphQLReleaseSource{params: params}
+}
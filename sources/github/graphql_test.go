@@ -0,0 +1,183 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/scylladb-actions/get-version/httpclient"
+	"github.com/scylladb-actions/get-version/types"
+	"github.com/scylladb-actions/get-version/version"
+)
+
+type graphQLStubRequest struct {
+	Query     string
+	Variables map[string]any
+}
+
+func newGraphQLStub(t *testing.T, pages map[string]string) *httptest.Server {
+	t.Helper()
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			t.Errorf("expected method %s, got %s", http.MethodPost, r.Method)
+		}
+		if auth := r.Header.Get("Authorization"); auth != "Bearer test-token" {
+			t.Errorf("expected Authorization header %q, got %q", "Bearer test-token", auth)
+		}
+		var rq graphQLStubRequest
+		if err := json.NewDecoder(r.Body).Decode(&rq); err != nil {
+			t.Errorf("failed to decode request: %v", err)
+		}
+		if rq.Variables["owner"] != "scylladb" || rq.Variables["name"] != "scylla" {
+			t.Errorf("unexpected variables %v", rq.Variables)
+		}
+		cursor, _ := rq.Variables["cursor"].(string)
+		page, ok := pages[cursor]
+		if !ok {
+			t.Errorf("unexpected cursor %q", cursor)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(page))
+	}))
+}
+
+func TestGraphQLTagSource(t *testing.T) {
+	server := newGraphQLStub(t, map[string]string{
+		"": `{"data":{"repository":{"items":{
+			"pageInfo":{"hasNextPage":true,"endCursor":"c1"},
+			"nodes":[
+				{"name":"scylla-6.2.0","target":{"oid":"aaa","committedDate":"2024-10-01T00:00:00Z"}},
+				{"name":"branch-point","target":{"oid":"bbb"}}
+			]}}}}`,
+		"c1": `{"data":{"repository":{"items":{
+			"pageInfo":{"hasNextPage":false,"endCursor":"c2"},
+			"nodes":[
+				{"name":"scylla-6.2.1","target":{"oid":"tag","target":{"oid":"ccc"},"tagger":{"date":"2024-11-01T00:00:00Z"}}}
+			]}}}}`,
+	})
+	defer server.Close()
+
+	params := types.Params{Repo: "scylladb/scylla", Prefix: "scylla-", GitHubToken: "test-token"}
+	versions, ignored, err := getVersionsFromGraphQL(
+		context.Background(), server.Client(), server.URL, graphQLTagsQuery, params,
+		func(tag graphQLTag) (string, version.Metadata, bool) {
+			return tag.Name, tag.metadata(), false
+		}, 0)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := versions.AsStringSlice(true); len(got) != 2 || got[0] != "scylla-6.2.0" || got[1] != "scylla-6.2.1" {
+		t.Fatalf("unexpected versions %v", got)
+	}
+	if len(ignored) != 1 || ignored[0].Version != "branch-point" {
+		t.Fatalf("unexpected ignored versions %v", ignored)
+	}
+	if commit := versions[0].Metadata()["commit"]; commit != "aaa" {
+		t.Errorf("expected commit %q for lightweight tag, got %q", "aaa", commit)
+	}
+	if commit := versions[1].Metadata()["commit"]; commit != "ccc" {
+		t.Errorf("expected commit %q for annotated tag, got %q", "ccc", commit)
+	}
+	if date := versions[1].Metadata()["date"]; date != "2024-11-01T00:00:00Z" {
+		t.Errorf("unexpected date %q for annotated tag", date)
+	}
+}
+
+func TestGraphQLReleaseSourceSkipsDrafts(t *testing.T) {
+	server := newGraphQLStub(t, map[string]string{
+		"": `{"data":{"repository":{"items":{
+			"pageInfo":{"hasNextPage":false},
+			"nodes":[
+				{"name":"6.2.0","tagName":"scylla-6.2.0","publishedAt":"2024-10-01T00:00:00Z","tagCommit":{"oid":"aaa"}},
+				{"name":"6.3.0","tagName":"scylla-6.3.0","isDraft":true}
+			]}}}}`,
+	})
+	defer server.Close()
+
+	params := types.Params{Repo: "scylladb/scylla", GitHubToken: "test-token"}
+	versions, _, err := getVersionsFromGraphQL(
+		context.Background(), server.Client(), server.URL, graphQLReleasesQuery, params,
+		func(release graphQLRelease) (string, version.Metadata, bool) {
+			return release.Name, release.metadata(), release.IsDraft
+		}, 0)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(versions) != 1 || versions[0].String() != "6.2.0" {
+		t.Fatalf("unexpected versions %v", versions.AsStringSlice(true))
+	}
+	if tag := versions[0].Metadata()["tag"]; tag != "scylla-6.2.0" {
+		t.Errorf("unexpected tag metadata %q", tag)
+	}
+}
+
+func TestGraphQLErrors(t *testing.T) {
+	server := newGraphQLStub(t, map[string]string{
+		"": `{"data":{"repository":null},"errors":[{"type":"NOT_FOUND","message":"Could not resolve to a Repository"}]}`,
+	})
+	defer server.Close()
+
+	params := types.Params{Repo: "scylladb/scylla", GitHubToken: "test-token"}
+	_, _, err := getVersionsFromGraphQL(
+		context.Background(), server.Client(), server.URL, graphQLTagsQuery, params,
+		func(tag graphQLTag) (string, version.Metadata, bool) {
+			return tag.Name, nil, false
+		}, 0)
+	if err == nil {
+		t.Fatalf("expected error for missing repository")
+	}
+}
+
+func TestGraphQLRateLimitRetries(t *testing.T) {
+	tcases := []struct {
+		name   string
+		status int
+		body   string
+	}{
+		{name: "rate limited response is retried by http client", status: http.StatusTooManyRequests},
+		{
+			name:   "rate limit in response body is retried by query",
+			status: http.StatusOK,
+			body:   `{"errors":[{"type":"RATE_LIMITED","message":"API rate limit exceeded"}]}`,
+		},
+	}
+
+	for _, tcase := range tcases {
+		t.Run(tcase.name, func(t *testing.T) {
+			var hits atomic.Int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				hits.Add(1)
+				w.WriteHeader(tcase.status)
+				_, _ = w.Write([]byte(tcase.body))
+			}))
+			defer server.Close()
+
+			params := types.Params{
+				Repo:              "scylladb/scylla",
+				GitHubToken:       "test-token",
+				RetryMax:          3,
+				RetryInitialDelay: 1,
+				RetryMaxDelay:     1,
+			}
+			cl, err := httpclient.New(params)
+			if err != nil {
+				t.Fatalf("failed to create http client: %v", err)
+			}
+			_, _, err = getVersionsFromGraphQL(
+				context.Background(), cl, server.URL, graphQLTagsQuery, params,
+				func(tag graphQLTag) (string, version.Metadata, bool) {
+					return tag.Name, nil, false
+				}, 0)
+			if !errors.Is(err, ErrRateLimited) {
+				t.Fatalf("expected rate limit error, got %v", err)
+			}
+			if got := hits.Load(); got != int32(params.RetryMax+1) {
+				t.Errorf("expected %d requests, got %d", params.RetryMax+1, got)
+			}
+		})
+	}
+}
@@ -2,6 +2,7 @@ package sources
 
 import (
 	"github.com/scylladb-actions/get-version/sources/docker"
+	"github.com/scylladb-actions/get-version/sources/file"
 	"github.com/scylladb-actions/get-version/sources/github"
 	"github.com/scylladb-actions/get-version/sources/maven"
 	"github.com/scylladb-actions/get-version/types"
@@ -9,9 +10,15 @@ import (
 
 var AllSources = types.Sources{
 	types.GitHubRelease: func(params types.Params) (types.Source, error) {
+		if params.UseGitHubGraphQL() {
+			return github.NewGraphQLReleaseSource(params), nil
+		}
 		return github.NewReleaseSource(params), nil
 	},
 	types.GitHubTag: func(params types.Params) (types.Source, error) {
+		if params.UseGitHubGraphQL() {
+			return github.NewGraphQLTagSource(params), nil
+		}
 		return github.NewTagSource(params), nil
 	},
 	types.DockerHubImageTag: func(params types.Params) (types.Source, error) {
@@ -20,4 +27,10 @@ var AllSources = types.Sources{
 	types.MavenArtifact: func(params types.Params) (types.Source, error) {
 		return maven.New(params)
 	},
+	types.File: func(params types.Params) (types.Source, error) {
+		return file.New(params)
+	},
+	types.Stdin: func(params types.Params) (types.Source, error) {
+		return file.NewStdin(params), nil
+	},
 }
@@ -1,6 +1,7 @@
 package maven
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
@@ -13,18 +14,22 @@ import (
 
 type versionExtractor func(r *http.Response) (version.Versions, []types.IgnoredVersion, error)
 
-func getURL(group, artifactID string) string {
-	return "https://search.maven.org/solrsearch/select?q=g:" +
+func getURL(apiURL, group, artifactID string) string {
+	if apiURL == "" {
+		apiURL = types.DefaultMavenURL
+	}
+	return strings.TrimSuffix(apiURL, "/") + "/solrsearch/select?q=g:" +
 		group + "%20AND%20a:" + artifactID + "&core=gav&rows=1000&wt=json"
 }
 
 func executeQuery(
+	ctx context.Context,
 	cl *http.Client,
 	url string,
 	extractor versionExtractor,
 ) (out version.Versions, ignored []types.IgnoredVersion, err error) {
 	var rq *http.Request
-	rq, err = http.NewRequest(http.MethodGet, url, nil)
+	rq, err = http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -33,6 +38,9 @@ func executeQuery(
 	if err != nil {
 		return nil, nil, fmt.Errorf("failed to execute http GET request for url %q: %w", url, err)
 	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
 	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
 		return nil, nil, fmt.Errorf("failed to execute http GET request for url %q, server replied with %s", url, resp.Status)
 	}
@@ -64,49 +72,29 @@ func extractVersions(resp *http.Response, prefix string) (version.Versions, []ty
 	var ignored []types.IgnoredVersion
 	for _, rec := range respBody.Response.Docs {
 		name := rec.Version
-		if prefix != "" && !strings.HasPrefix(name, prefix) {
-			ignored = append(ignored, types.IgnoredVersion{
-				Version: name,
-				Reason:  fmt.Errorf("version %q does not have prefix %q", name, prefix),
-			})
-			continue
-		}
-		name = strings.TrimPrefix(name, prefix)
-		ver, err := version.New(name)
-		if err != nil {
-			ignored = append(ignored, types.IgnoredVersion{Version: name, Reason: err})
+		ver, ignoredVersion := types.NewVersion(name, prefix)
+		if ignoredVersion != nil {
+			ignored = append(ignored, *ignoredVersion)
 			continue
 		}
-		ver.SetPrefix(prefix)
 		out = append(out, ver)
 	}
 	return out, ignored, nil
 }
 
-func getVersionsFromMVN(
-	cl *http.Client,
-	url string,
-	extractor versionExtractor,
-) (version.Versions, []types.IgnoredVersion, error) {
-	for retry := 0; ; retry++ {
-		versions, ignoredVersions, err := executeQuery(cl, url, extractor)
-		if err == nil {
-			return versions, ignoredVersions, nil
-		}
-		if retry > 5 {
-			return nil, nil, fmt.Errorf("failed to execute query to %s, last error: %w", url, err)
-		}
-	}
-}
-
 type Source struct {
 	params types.Params
 }
 
-func (s Source) GetAllVersions() (version.Versions, []types.IgnoredVersion, error) {
-	return getVersionsFromMVN(
-		httpclient.New(s.params),
-		getURL(s.params.MavenGroup, s.params.MavenArtifactID),
+func (s Source) GetAllVersions(ctx context.Context) (version.Versions, []types.IgnoredVersion, error) {
+	cl, err := httpclient.New(s.params)
+	if err != nil {
+		return nil, nil, err
+	}
+	return executeQuery(
+		ctx,
+		cl,
+		getURL(s.params.MavenURL, s.params.MavenGroup, s.params.MavenArtifactID),
 		func(r *http.Response) (version.Versions, []types.IgnoredVersion, error) {
 			return extractVersions(r, s.params.Prefix)
 		})
@@ -0,0 +1,71 @@
+package maven
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/scylladb-actions/get-version/sources/maven/maventest"
+	"github.com/scylladb-actions/get-version/types"
+)
+
+func TestSourceGetAllVersions(t *testing.T) {
+	server := maventest.NewServer()
+	defer server.Close()
+
+	source, err := New(types.Params{
+		MavenGroup:      maventest.Group,
+		MavenArtifactID: maventest.ArtifactID,
+		MavenURL:        server.URL,
+	})
+	if err != nil {
+		t.Fatalf("failed to create source: %v", err)
+	}
+	versions, ignored, err := source.GetAllVersions(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := []string{"4.18.0.0", "4.17.0.1", "3.11.5.4"}
+	if got := versions.AsStringSlice(true); !slices.Equal(got, expected) {
+		t.Fatalf("expected versions %v, got %v", expected, got)
+	}
+	if len(ignored) != 1 || ignored[0].Version != "snapshot" {
+		t.Fatalf("unexpected ignored versions %v", ignored)
+	}
+}
+
+// TestSourceReplay records responses of the source and replays them after the server is gone,
+// new sources can be tested against fixtures recorded from real APIs the same way.
+func TestSourceReplay(t *testing.T) {
+	server := maventest.NewServer()
+	fixtures := t.TempDir()
+	params := types.Params{
+		MavenGroup:      maventest.Group,
+		MavenArtifactID: maventest.ArtifactID,
+		MavenURL:        server.URL,
+		HTTPRecord:      fixtures,
+	}
+
+	source, err := New(params)
+	if err != nil {
+		t.Fatalf("failed to create source: %v", err)
+	}
+	recorded, _, err := source.GetAllVersions(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	server.Close()
+
+	params.HTTPRecord, params.HTTPReplay = "", fixtures
+	source, err = New(params)
+	if err != nil {
+		t.Fatalf("failed to create source: %v", err)
+	}
+	replayed, _, err := source.GetAllVersions(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !slices.Equal(recorded.AsStringSlice(true), replayed.AsStringSlice(true)) {
+		t.Fatalf("expected replayed versions %v, got %v", recorded.AsStringSlice(true), replayed.AsStringSlice(true))
+	}
+}
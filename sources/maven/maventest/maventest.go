@@ -0,0 +1,25 @@
+// Package maventest provides a Maven Central search API stub for tests of code that runs queries.
+package maventest
+
+import (
+	"net/http"
+	"net/http/httptest"
+)
+
+const (
+	// Group and ArtifactID are the artifact the stub has versions of, other artifacts are not found.
+	Group      = "com.scylladb"
+	ArtifactID = "java-driver-core"
+)
+
+// NewServer starts the stub, its URL is the --mvn-url of queries, the caller closes it.
+// The artifact has versions 4.18.0.0, 4.17.0.1 and 3.11.5.4, and snapshot, which is not a version.
+func NewServer() *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/solrsearch/select" || r.URL.Query().Get("q") != "g:"+Group+" AND a:"+ArtifactID {
+			http.Error(w, "unknown artifact", http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write([]byte(`{"response":{"docs":[{"v":"4.18.0.0"},{"v":"4.17.0.1"},{"v":"3.11.5.4"},{"v":"snapshot"}]}}`))
+	}))
+}
@@ -0,0 +1,43 @@
+package types
+
+import (
+	"fmt"
+	"slices"
+)
+
+// BumpName is the part of the version --bump increments.
+type BumpName string
+
+const (
+	BumpPatch BumpName = "patch"
+	BumpMinor BumpName = "minor"
+	BumpMajor BumpName = "major"
+	// BumpRC increments release candidate number, or starts release candidates of the next version
+	BumpRC BumpName = "rc"
+)
+
+var knownBumpNames = []BumpName{BumpPatch, BumpMinor, BumpMajor, BumpRC}
+
+// NextMode tells whether the next release version is computed instead of listing versions.
+func (p Params) NextMode() bool {
+	return p.Bump != ""
+}
+
+func (p Params) validateNextMode() error {
+	if p.Bump != "" && !slices.Contains(knownBumpNames, p.Bump) {
+		return fmt.Errorf("unknown bump %q, it should be one of: patch, minor, major, rc", p.Bump)
+	}
+	if p.Branch != "" && !p.NextMode() {
+		return fmt.Errorf("--branch requires --bump")
+	}
+	if p.Branch != "" && p.Bump != BumpPatch && p.Bump != BumpRC {
+		return fmt.Errorf("--branch allows only --bump patch or --bump rc, the branch fixes major and minor")
+	}
+	if p.NextMode() && p.CheckMode() {
+		return fmt.Errorf("--bump can't be used with --current or --current-file")
+	}
+	if p.NextMode() && !slices.Contains(checkOutputNames, p.OutFormat) {
+		return fmt.Errorf("output format %q is not supported with --bump", p.OutFormat)
+	}
+	return nil
+}
@@ -0,0 +1,130 @@
+package types
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// CommandName is the subcommand given by the first argument, args that start with a flag run CommandList.
+type CommandName string
+
+const (
+	CommandList           CommandName = "list"
+	CommandLatest         CommandName = "latest"
+	CommandCheck          CommandName = "check"
+	CommandUpdate         CommandName = "update"
+	CommandNext           CommandName = "next"
+	CommandValidateFilter CommandName = "validate-filter"
+	CommandExplain        CommandName = "explain"
+	CommandSources        CommandName = "sources"
+	CommandCompletion     CommandName = "completion"
+	CommandServe          CommandName = "serve"
+)
+
+// Command describes a subcommand for usage.
+type Command struct {
+	Name  CommandName
+	Usage string
+}
+
+// Commands are the known subcommands in the order of usage.
+var Commands = []Command{
+	{Name: CommandList, Usage: "list versions selected by --filters, the default command"},
+	{Name: CommandLatest, Usage: "print the newest version selected by --filters"},
+	{Name: CommandCheck, Usage: "compare --current or --current-file against the newest selected version"},
+	{Name: CommandUpdate, Usage: "write the newest selected version to --current-file, check with --update"},
+	{Name: CommandNext, Usage: "print the next release version, incrementing the newest selected one by --bump"},
+	{Name: CommandValidateFilter, Usage: "validate-filter [filter]: check the filter, or --filters, without a source"},
+	{Name: CommandExplain, Usage: "explain how --filters selects versions, with --source also what it selects"},
+	{Name: CommandSources, Usage: "list sources and the flags they take"},
+	{Name: CommandCompletion, Usage: "completion bash|zsh|fish: print a shell completion script"},
+	{Name: CommandServe, Usage: "serve queries over HTTP on --listen, see /v1/versions, /healthz and /metrics"},
+}
+
+// CompletionShells are the shells completion command supports.
+var CompletionShells = []string{"bash", "zsh", "fish"}
+
+// SplitCommand returns the command given by the first of args and the rest of args.
+func SplitCommand(args []string) (CommandName, []string) {
+	if len(args) == 0 || strings.HasPrefix(args[0], "-") {
+		return CommandList, args
+	}
+	return CommandName(args[0]), args[1:]
+}
+
+func knownCommand(name CommandName) bool {
+	return slices.ContainsFunc(Commands, func(command Command) bool {
+		return command.Name == name
+	})
+}
+
+// NeedsSource tells whether the command gets versions from a source, explain does it only when --source is set.
+func (c CommandName) NeedsSource() bool {
+	switch c {
+	case CommandValidateFilter, CommandExplain, CommandSources, CommandCompletion, CommandServe:
+		return false
+	default:
+		return true
+	}
+}
+
+// validateArgs checks positional args of the command, filter of validate-filter can also be given by --filters
+func (p *Params) validateArgs() error {
+	switch p.Command {
+	case CommandValidateFilter:
+		if len(p.Args) > 1 {
+			return fmt.Errorf("validate-filter takes a single filter, quote filters with spaces")
+		}
+		if len(p.Args) == 1 {
+			p.FiltersDefinition = p.Args[0]
+		}
+	case CommandCompletion:
+		if len(p.Args) != 1 || !slices.Contains(CompletionShells, p.Args[0]) {
+			return fmt.Errorf("completion takes a shell: %s", strings.Join(CompletionShells, ", "))
+		}
+	default:
+		if len(p.Args) != 0 {
+			return fmt.Errorf("unexpected arguments %q", p.Args)
+		}
+	}
+	if p.Config != "" && !p.Command.NeedsSource() {
+		return fmt.Errorf("--config can't be used with %s command", p.Command)
+	}
+	return nil
+}
+
+// applyCommand turns on flags that commands stand for, update is check with --update
+func (p *Params) applyCommand() {
+	if p.Command == CommandUpdate {
+		p.Update = true
+	}
+}
+
+// validateCommand checks that params are of the kind the command works with
+func (p Params) validateCommand() error {
+	switch p.Command {
+	case CommandCheck:
+		if !p.CheckMode() {
+			return fmt.Errorf("check command requires --current or --current-file")
+		}
+	case CommandUpdate:
+		if p.CurrentFile == "" || p.NextMode() {
+			return fmt.Errorf("update command requires --current-file and can't be used with --bump")
+		}
+	case CommandNext:
+		if !p.NextMode() {
+			return fmt.Errorf("next command requires --bump: patch, minor, major or rc")
+		}
+	case CommandLatest:
+		if p.CheckMode() || p.NextMode() {
+			return fmt.Errorf("latest command can't be used with --current, --current-file or --bump")
+		}
+	case CommandServe:
+		if p.SourceName != "" || p.CheckMode() || p.NextMode() || p.OutAsAction {
+			return fmt.Errorf("serve command takes queries from requests, " +
+				"--source, --current, --current-file, --bump and --out-as-action can't be used with it")
+		}
+	}
+	return nil
+}
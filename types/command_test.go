@@ -0,0 +1,95 @@
+package types
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestSplitCommand(t *testing.T) {
+	tcases := []struct {
+		args     []string
+		command  CommandName
+		expected []string
+	}{
+		{args: nil, command: CommandList},
+		{args: []string{"--source", "github-tag"}, command: CommandList, expected: []string{"--source", "github-tag"}},
+		{args: []string{"latest", "--source=github-tag"}, command: CommandLatest, expected: []string{"--source=github-tag"}},
+		{args: []string{"validate-filter", "LAST"}, command: CommandValidateFilter, expected: []string{"LAST"}},
+	}
+
+	for _, tcase := range tcases {
+		t.Run(string(tcase.command), func(t *testing.T) {
+			command, args := SplitCommand(tcase.args)
+			if command != tcase.command || !slices.Equal(args, tcase.expected) {
+				t.Errorf("expected %s %v, got %s %v", tcase.command, tcase.expected, command, args)
+			}
+		})
+	}
+}
+
+func TestValidateCommand(t *testing.T) {
+	tcases := []struct {
+		name   string
+		params Params
+		valid  bool
+	}{
+		{name: "list", params: Params{Command: CommandList, Args: []string{}}, valid: true},
+		{name: "list with args", params: Params{Command: CommandList, Args: []string{"LAST"}}},
+		{name: "check", params: Params{Command: CommandCheck, Current: "6.2.0"}, valid: true},
+		{name: "check without current", params: Params{Command: CommandCheck}},
+		{name: "latest with bump", params: Params{Command: CommandLatest, Bump: BumpPatch}},
+		{name: "update", params: Params{Command: CommandUpdate, CurrentFile: "Dockerfile"}, valid: true},
+		{name: "update without current file", params: Params{Command: CommandUpdate, Current: "6.2.0"}},
+		{name: "update with bump", params: Params{Command: CommandUpdate, CurrentFile: "Dockerfile", Bump: BumpPatch}},
+		{name: "next", params: Params{Command: CommandNext, Bump: BumpRC}, valid: true},
+		{name: "next without bump", params: Params{Command: CommandNext}},
+		{name: "validate filter", params: Params{Command: CommandValidateFilter, Args: []string{"LAST"}}, valid: true},
+		{name: "validate two filters", params: Params{Command: CommandValidateFilter, Args: []string{"LAST", "FIRST"}}},
+		{name: "completion", params: Params{Command: CommandCompletion, Args: []string{"zsh"}}, valid: true},
+		{name: "completion of unknown shell", params: Params{Command: CommandCompletion, Args: []string{"tcsh"}}},
+		{name: "sources with config", params: Params{Command: CommandSources, Config: "get-version.yaml"}},
+		{name: "serve", params: Params{Command: CommandServe, Cache: true}, valid: true},
+		{name: "serve with source", params: Params{Command: CommandServe, SourceName: GitHubTag}},
+		{name: "serve with bump", params: Params{Command: CommandServe, Bump: BumpPatch}},
+	}
+
+	for _, tcase := range tcases {
+		t.Run(tcase.name, func(t *testing.T) {
+			err := tcase.params.validateArgs()
+			if err == nil {
+				tcase.params.applyCommand()
+				err = tcase.params.validateCommand()
+			}
+			if tcase.params.Update != (tcase.params.Command == CommandUpdate) {
+				t.Errorf("expected --update to be set only by update command")
+			}
+			if (err == nil) != tcase.valid {
+				t.Errorf("expected valid %v, got error %v", tcase.valid, err)
+			}
+		})
+	}
+}
+
+func TestValidateSourceFlags(t *testing.T) {
+	set := func(names ...string) func(string) bool {
+		return func(name string) bool {
+			return slices.Contains(names, name)
+		}
+	}
+	if err := ValidateSourceFlags(GitHubTag, set("repo", "github-api", "prefix")); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if err := ValidateSourceFlags(GitHubTag, set("repo", "mvn-group")); err == nil {
+		t.Errorf("expected error for --mvn-group of github-tag")
+	}
+	if err := ValidateSourceFlags(MavenArtifact, set("repo")); err == nil {
+		t.Errorf("expected error for --repo of maven-artifact")
+	}
+
+	if err := (Params{SourceName: MavenArtifact, MavenGroup: "com.scylladb"}).validateRequiredFlags(); err == nil {
+		t.Errorf("expected error for missing --mvn-artifact-id")
+	}
+	if err := (Params{SourceName: DockerHubImageTag, Repo: "scylladb/scylla"}).validateRequiredFlags(); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
@@ -0,0 +1,269 @@
+package types
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Config is a file with named queries, keys of settings and queries are names of flags without dashes.
+//
+//	settings:
+//	  cache-dir: .cache
+//	queries:
+//	  - name: scylla
+//	    source: github-release
+//	    repo: scylladb/scylla
+//	    filters: LAST
+//	sets:
+//	  - name: missing-images
+//	    difference: [scylla, scylla-images]
+//	    max-count: 0
+type Config struct {
+	// Settings apply to every query and set
+	Settings map[string]any   `yaml:"settings"`
+	Queries  []map[string]any `yaml:"queries"`
+	// Sets combine versions selected by queries or by sets listed before them, a set has one of keys
+	// union, intersection and difference with the list of names, other keys are flags as for queries
+	// except for the source ones. Versions of a set are selected again by its filters.
+	Sets []map[string]any `yaml:"sets"`
+}
+
+// Query is a named query of a config file.
+type Query struct {
+	Name   string
+	Params Params
+}
+
+// LoadConfig reads queries from a config file. Params of a query are its settings on top of the common ones,
+// with flags from args on top of both. Flags with empty or default values are skipped, since they can't be told
+// from flags that are not set, e.g. by the action that passes all its inputs.
+// Output name of a query is its name unless it is set. Sets follow queries in the returned order.
+// Command given by the first of args is set for every query, flags that apply only to other sources
+// are rejected in settings of a query, but not in common settings and flags.
+func LoadConfig(path string, args []string, knownSources Sources) ([]Query, error) {
+	command, args := SplitCommand(args)
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config %q: %w", path, err)
+	}
+	var config Config
+	if err = yaml.Unmarshal(content, &config); err != nil {
+		return nil, fmt.Errorf("failed to parse config %q: %w", path, err)
+	}
+	if len(config.Queries) == 0 {
+		return nil, fmt.Errorf("config %q has no queries", path)
+	}
+
+	queries := make([]Query, 0, len(config.Queries)+len(config.Sets))
+	names := map[string]bool{}
+	for i, settings := range config.Queries {
+		name, err := queryName("query", i, settings, names)
+		if err != nil {
+			return nil, fmt.Errorf("config %q: %w", path, err)
+		}
+
+		params, err := loadQuery(name, config.Settings, settings, args, knownSources)
+		if err == nil {
+			err = ValidateSourceFlags(params.SourceName, func(name string) bool {
+				value, ok := settings[name]
+				return ok && value != nil && value != ""
+			})
+		}
+		if err == nil {
+			params.Command = command
+			err = params.complete(knownSources)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("config %q: query %q: %w", path, name, err)
+		}
+		queries = append(queries, Query{Name: name, Params: params})
+	}
+	for i, settings := range config.Sets {
+		name, err := queryName("set", i, settings, names)
+		if err != nil {
+			return nil, fmt.Errorf("config %q: %w", path, err)
+		}
+
+		params, err := loadSet(name, config.Settings, settings, args, names, knownSources)
+		if err == nil {
+			params.Command = command
+			err = params.complete(knownSources)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("config %q: set %q: %w", path, name, err)
+		}
+		queries = append(queries, Query{Name: name, Params: params})
+	}
+	return queries, nil
+}
+
+// queryName returns name of a query or a set and adds it to names, names are unique across queries and sets
+func queryName(kind string, i int, settings map[string]any, names map[string]bool) (string, error) {
+	name, _ := settings["name"].(string)
+	if name == "" {
+		return "", fmt.Errorf("%s %d has no name", kind, i+1)
+	}
+	if names[name] {
+		return "", fmt.Errorf("%s name %q is not unique", kind, name)
+	}
+	names[name] = true
+	return name, nil
+}
+
+// loadSet loads a set like a query, the set operation key names queries that are defined before the set.
+// Params of the set are validated by complete.
+func loadSet(
+	name string,
+	common, settings map[string]any,
+	args []string,
+	names map[string]bool,
+	knownSources Sources,
+) (Params, error) {
+	var (
+		operation SetOperation
+		of        []string
+		rest      = map[string]any{}
+	)
+	for key, value := range settings {
+		if !slices.Contains(knownSetOperations, SetOperation(key)) {
+			if key == "source" || key == "repo" {
+				return Params{}, fmt.Errorf("%s can't be set for a set, it combines versions of queries", key)
+			}
+			rest[key] = value
+			continue
+		}
+		if operation != "" {
+			return Params{}, fmt.Errorf("set has both %s and %s", operation, key)
+		}
+		operation = SetOperation(key)
+		items, _ := value.([]any)
+		for _, item := range items {
+			ref, _ := item.(string)
+			if ref == name || !names[ref] {
+				return Params{}, fmt.Errorf("%s: unknown query %q, queries should be listed before the set", key, item)
+			}
+			of = append(of, ref)
+		}
+	}
+	if operation == "" {
+		return Params{}, fmt.Errorf("set has none of union, intersection and difference")
+	}
+	if len(of) < 2 {
+		return Params{}, fmt.Errorf("%s needs at least two queries", operation)
+	}
+
+	params, err := loadQuery(name, common, rest, args, knownSources)
+	if err != nil {
+		return Params{}, err
+	}
+	// Source from common settings or from flags is for queries
+	params.SourceName = ""
+	params.Combine = operation
+	params.CombineOf = of
+	return params, nil
+}
+
+// loadQuery parses params of a query, they are validated by complete
+func loadQuery(name string, common, settings map[string]any, args []string, knownSources Sources) (Params, error) {
+	var p Params
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	p.register(fs, knownSources)
+
+	for _, values := range []map[string]any{common, settings} {
+		for key, value := range values {
+			if key == "name" {
+				continue
+			}
+			if key == "config" {
+				return Params{}, fmt.Errorf("config can't be set in config")
+			}
+			if err := setFlag(fs, key, value); err != nil {
+				return Params{}, err
+			}
+		}
+	}
+	if err := fs.Parse(overrides(fs, args)); err != nil {
+		return Params{}, err
+	}
+	// Visit walks flags that were set either by config or by args
+	outNameSet := false
+	fs.Visit(func(f *flag.Flag) {
+		outNameSet = outNameSet || f.Name == "out-name"
+	})
+	if !outNameSet {
+		p.OutName = name
+	}
+	p.Config = ""
+	p.Log = os.Stderr
+	return p, nil
+}
+
+// setFlag sets a flag from a config value, every item of a list is set in turn, like a repeated flag
+func setFlag(fs *flag.FlagSet, key string, value any) error {
+	items, ok := value.([]any)
+	if !ok {
+		items = []any{value}
+	}
+	for _, item := range items {
+		if item == nil {
+			item = ""
+		}
+		if err := fs.Set(key, fmt.Sprint(item)); err != nil {
+			return fmt.Errorf("failed to set %s: %w", key, err)
+		}
+	}
+	return nil
+}
+
+// rawValue records values a flag of args is set to
+type rawValue struct {
+	values []string
+	isBool bool
+}
+
+func (v *rawValue) String() string {
+	return strings.Join(v.values, ",")
+}
+
+func (v *rawValue) Set(value string) error {
+	v.values = append(v.values, value)
+	return nil
+}
+
+func (v *rawValue) IsBoolFlag() bool {
+	return v.isBool
+}
+
+// overrides returns flags that are set explicitly in args, so that they override the config
+// even when they are set to default values. Flags with empty values are skipped, the action passes
+// inputs that are not given that way. Args that fail parsing are returned as they are to fail it again.
+func overrides(fs *flag.FlagSet, args []string) []string {
+	explicit := flag.NewFlagSet(fs.Name(), flag.ContinueOnError)
+	explicit.SetOutput(io.Discard)
+	fs.VisitAll(func(f *flag.Flag) {
+		boolFlag, ok := f.Value.(interface{ IsBoolFlag() bool })
+		explicit.Var(&rawValue{isBool: ok && boolFlag.IsBoolFlag()}, f.Name, f.Usage)
+	})
+	if err := explicit.Parse(args); err != nil {
+		return args
+	}
+	var out []string
+	explicit.Visit(func(f *flag.Flag) {
+		for _, value := range f.Value.(*rawValue).values {
+			if value != "" {
+				out = append(out, "--"+f.Name+"="+value)
+			}
+		}
+	})
+	if explicit.NArg() != 0 {
+		out = append(append(out, "--"), explicit.Args()...)
+	}
+	return out
+}
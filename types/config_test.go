@@ -0,0 +1,211 @@
+package types
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+)
+
+const testConfig = `
+settings:
+  cache-dir: .cache
+  filters: LAST
+  timeout: 5m
+queries:
+  - name: scylla
+    source: github-release
+    repo: scylladb/scylla
+    prefix: scylla-
+  - name: cassandra
+    source: dockerhub-imagetag
+    repo: library/cassandra
+    filters: LAST.*.*
+    out-name: cassandra-versions
+    out-format: matrix
+    out-matrix-extra:
+      - os=ubuntu-24.04
+      - arch=amd64
+`
+
+func TestLoadConfig(t *testing.T) {
+	knownSources := Sources{GitHubRelease: nil, DockerHubImageTag: nil}
+	path := filepath.Join(t.TempDir(), "get-version.yaml")
+	if err := os.WriteFile(path, []byte(testConfig), 0o644); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+
+	args := []string{"--config", path, "--timeout=1m", "--filters=", "--cache-ttl=10m"}
+	queries, err := LoadConfig(path, args, knownSources)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(queries) != 2 || queries[0].Name != "scylla" || queries[1].Name != "cassandra" {
+		t.Fatalf("unexpected queries %v", queries)
+	}
+
+	scylla := queries[0].Params
+	if scylla.SourceName != GitHubRelease || scylla.Repo != "scylladb/scylla" || scylla.Prefix != "scylla-" {
+		t.Errorf("unexpected query params %+v", scylla)
+	}
+	if scylla.FiltersDefinition != "LAST" || !scylla.Cache || scylla.CacheDir != ".cache" {
+		t.Errorf("settings are not applied: %+v", scylla)
+	}
+	if scylla.Timeout != time.Minute {
+		t.Errorf("expected timeout from flags %s, got %s", time.Minute, scylla.Timeout)
+	}
+	if scylla.OutName != "scylla" || scylla.OutFormat != OutputTEXT || scylla.Config != "" {
+		t.Errorf("unexpected defaults: %+v", scylla)
+	}
+
+	cassandra := queries[1].Params
+	if cassandra.FiltersDefinition != "LAST.*.*" || cassandra.OutName != "cassandra-versions" ||
+		cassandra.OutFormat != OutputMatrix {
+		t.Errorf("query settings do not override common ones: %+v", cassandra)
+	}
+	if !slices.Equal(cassandra.OutMatrixExtra, []string{"os=ubuntu-24.04", "arch=amd64"}) {
+		t.Errorf("unexpected matrix extra %v", cassandra.OutMatrixExtra)
+	}
+}
+
+const testQueries = "queries:\n  - name: a\n    source: github-release\n    repo: scylladb/scylla\n" +
+	"  - name: b\n    source: github-release\n    repo: scylladb/scylla-machine-image\n"
+
+func TestLoadConfigSets(t *testing.T) {
+	knownSources := Sources{GitHubRelease: nil}
+	path := filepath.Join(t.TempDir(), "get-version.yaml")
+	config := "settings:\n  source: github-release\n  filters: LAST\n" + testQueries +
+		"sets:\n  - name: missing\n    difference: [a, b]\n    max-count: 0\n" +
+		"  - name: all\n    union: [missing, b]\n    filters: LAST.*.*\n"
+	if err := os.WriteFile(path, []byte(config), 0o644); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+
+	queries, err := LoadConfig(path, []string{"--out-format=text"}, knownSources)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(queries) != 4 || queries[2].Name != "missing" || queries[3].Name != "all" {
+		t.Fatalf("unexpected queries %v", queries)
+	}
+
+	missing := queries[2].Params
+	if missing.Combine != SetDifference || !slices.Equal(missing.CombineOf, []string{"a", "b"}) ||
+		missing.SourceName != "" {
+		t.Errorf("unexpected set params %+v", missing)
+	}
+	if missing.MaxCount != 0 || missing.FiltersDefinition != "LAST" || missing.OutName != "missing" ||
+		missing.OutFormat != OutputTEXT {
+		t.Errorf("settings are not applied to set: %+v", missing)
+	}
+	all := queries[3].Params
+	if all.Combine != SetUnion || !slices.Equal(all.CombineOf, []string{"missing", "b"}) ||
+		all.FiltersDefinition != "LAST.*.*" {
+		t.Errorf("unexpected set params %+v", all)
+	}
+}
+
+func TestLoadConfigErrors(t *testing.T) {
+	knownSources := Sources{GitHubRelease: nil}
+	tcases := []struct {
+		name   string
+		config string
+	}{
+		{name: "no queries", config: "settings:\n  filters: LAST\n"},
+		{name: "no name", config: "queries:\n  - source: github-release\n    repo: scylladb/scylla\n"},
+		{name: "duplicate name", config: "queries:\n  - name: a\n    source: github-release\n" +
+			"  - name: a\n    source: github-release\n"},
+		{name: "unknown key", config: "queries:\n  - name: a\n    source: github-release\n    sorce: x\n"},
+		{name: "nested config", config: "queries:\n  - name: a\n    source: github-release\n    config: b.yaml\n"},
+		{name: "invalid query", config: "queries:\n  - name: a\n    source: maven-artifact\n"},
+		{name: "flag of other source", config: "queries:\n  - name: a\n    source: github-release\n" +
+			"    repo: scylladb/scylla\n    mvn-group: com.scylladb\n"},
+		{name: "set without operation", config: testQueries + "sets:\n  - name: s\n    filters: LAST\n"},
+		{name: "set with two operations", config: testQueries + "sets:\n  - name: s\n    union: [a, b]\n" +
+			"    difference: [a, b]\n"},
+		{name: "set of one query", config: testQueries + "sets:\n  - name: s\n    union: [a]\n"},
+		{name: "set of unknown query", config: testQueries + "sets:\n  - name: s\n    union: [a, c]\n"},
+		{name: "set of itself", config: testQueries + "sets:\n  - name: s\n    union: [a, s]\n"},
+		{name: "set with source", config: testQueries + "sets:\n  - name: s\n    union: [a, b]\n" +
+			"    source: github-release\n"},
+		{name: "set name of query", config: testQueries + "sets:\n  - name: a\n    union: [a, b]\n"},
+	}
+
+	for _, tcase := range tcases {
+		t.Run(tcase.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "get-version.yaml")
+			if err := os.WriteFile(path, []byte(tcase.config), 0o644); err != nil {
+				t.Fatalf("failed to write config: %v", err)
+			}
+			if _, err := LoadConfig(path, nil, knownSources); err == nil {
+				t.Errorf("expected error")
+			}
+		})
+	}
+}
+
+func TestOverrides(t *testing.T) {
+	var p Params
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	p.register(fs, Sources{})
+
+	tcases := []struct {
+		name     string
+		args     []string
+		expected []string
+	}{
+		{
+			name: "explicit flags",
+			args: []string{
+				"--source=github-tag", "--repo", "scylladb/scylla", "--filters=", "--out-format=text",
+				"--out-as-action", "--early-stop=false", "-expect-count", "", "--max-count=2",
+			},
+			expected: []string{
+				"--early-stop=false", "--max-count=2", "--out-as-action=true", "--out-format=text",
+				"--repo=scylladb/scylla", "--source=github-tag",
+			},
+		},
+		{
+			name:     "repeated flag and args",
+			args:     []string{"--out-matrix-extra=os=ubuntu", "--out-matrix-extra", "arch=amd64", "--", "-x"},
+			expected: []string{"--out-matrix-extra=os=ubuntu", "--out-matrix-extra=arch=amd64", "--", "-x"},
+		},
+		{
+			name:     "unknown flag",
+			args:     []string{"--source=github-tag", "--bogus"},
+			expected: []string{"--source=github-tag", "--bogus"},
+		},
+	}
+
+	for _, tcase := range tcases {
+		t.Run(tcase.name, func(t *testing.T) {
+			if got := overrides(fs, tcase.args); !slices.Equal(got, tcase.expected) {
+				t.Errorf("expected %v, got %v", tcase.expected, got)
+			}
+		})
+	}
+}
+
+func TestLoadConfigFlagsResetSettings(t *testing.T) {
+	knownSources := Sources{GitHubRelease: nil}
+	path := filepath.Join(t.TempDir(), "get-version.yaml")
+	config := "settings:\n  out-no-prefix: true\n  retry-max: 5\n  filters: LAST\n" + testQueries
+	if err := os.WriteFile(path, []byte(config), 0o644); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+
+	queries, err := LoadConfig(path, []string{"--out-no-prefix=false", "--retry-max=3", "--filters="}, knownSources)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, query := range queries {
+		if query.Params.OutNoPrefix || query.Params.RetryMax != 3 {
+			t.Errorf("%s: flags set to defaults do not override settings: %+v", query.Name, query.Params)
+		}
+		if query.Params.FiltersDefinition != "LAST" {
+			t.Errorf("%s: empty flag overrides settings: %q", query.Name, query.Params.FiltersDefinition)
+		}
+	}
+}
@@ -0,0 +1,57 @@
+package types
+
+import (
+	"sync"
+)
+
+type credentialsKey struct {
+	source SourceName
+	repo   string
+}
+
+type credentialsEntry struct {
+	mu    sync.Mutex
+	value any
+}
+
+// Credentials keeps credentials of sources by source and repo, like a GitHub App installation token,
+// so that queries of serve share them instead of creating new ones for every query.
+type Credentials struct {
+	mu      sync.Mutex
+	entries map[credentialsKey]*credentialsEntry
+}
+
+func NewCredentials() *Credentials {
+	return &Credentials{entries: map[credentialsKey]*credentialsEntry{}}
+}
+
+func (c *Credentials) entry(key credentialsKey) *credentialsEntry {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry, ok := c.entries[key]
+	if !ok {
+		entry = &credentialsEntry{}
+		c.entries[key] = entry
+	}
+	return entry
+}
+
+// SharedCredentials returns credentials of the source and repo of params kept in p.Credentials, create makes them
+// when there are none yet, failures are not kept. Without p.Credentials every call creates new credentials.
+func SharedCredentials[T any](p Params, create func() (T, error)) (T, error) {
+	if p.Credentials == nil {
+		return create()
+	}
+	entry := p.Credentials.entry(credentialsKey{source: p.SourceName, repo: p.Repo})
+	entry.mu.Lock()
+	defer entry.mu.Unlock()
+	if value, ok := entry.value.(T); ok {
+		return value, nil
+	}
+	value, err := create()
+	if err != nil {
+		return value, err
+	}
+	entry.value = value
+	return value, nil
+}
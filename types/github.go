@@ -0,0 +1,63 @@
+package types
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+)
+
+// DefaultGitHubAPIURL is the API base URL of github.com.
+const DefaultGitHubAPIURL = "https://api.github.com"
+
+type GitHubAPIName string
+
+const (
+	// GitHubAPIAuto picks GraphQL when a token is available and REST otherwise,
+	// GraphQL API does not allow anonymous requests.
+	GitHubAPIAuto    GitHubAPIName = "auto"
+	GitHubAPIREST    GitHubAPIName = "rest"
+	GitHubAPIGraphQL GitHubAPIName = "graphql"
+)
+
+var knownGitHubAPINames = []GitHubAPIName{GitHubAPIAuto, GitHubAPIREST, GitHubAPIGraphQL}
+
+// UseGitHubGraphQL reports whether GitHub sources should be queried through GraphQL API.
+func (p Params) UseGitHubGraphQL() bool {
+	switch p.GitHubAPI {
+	case GitHubAPIGraphQL:
+		return true
+	case GitHubAPIREST:
+		return false
+	default:
+		return p.HasGitHubCredentials()
+	}
+}
+
+// UseGitHubApp reports whether GitHub requests should be authenticated as a GitHub App installation,
+// App credentials take precedence over GitHub token.
+func (p Params) UseGitHubApp() bool {
+	return p.GitHubAppID != ""
+}
+
+func (p Params) HasGitHubCredentials() bool {
+	return p.GitHubToken != "" || p.UseGitHubApp()
+}
+
+func (p *Params) loadGitHubAppFromEnv() error {
+	if p.GitHubAppID == "" {
+		p.GitHubAppID = os.Getenv("GITHUB_APP_ID")
+	}
+	if p.GitHubAppPrivateKey == "" {
+		p.GitHubAppPrivateKey = os.Getenv("GITHUB_APP_PRIVATE_KEY")
+	}
+	if p.GitHubAppInstallationID == 0 {
+		if value := os.Getenv("GITHUB_APP_INSTALLATION_ID"); value != "" {
+			id, err := strconv.ParseInt(value, 10, 64)
+			if err != nil {
+				return fmt.Errorf("failed to parse GITHUB_APP_INSTALLATION_ID %q: %w", value, err)
+			}
+			p.GitHubAppInstallationID = id
+		}
+	}
+	return nil
+}
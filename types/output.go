@@ -1,6 +1,10 @@
 package types
 
-import "github.com/scylladb-actions/get-version/version"
+import (
+	"regexp"
+
+	"github.com/scylladb-actions/get-version/version"
+)
 
 type OutputName string
 
@@ -8,9 +12,21 @@ const (
 	OutputTEXT OutputName = "text"
 	OutputJSON OutputName = "json"
 	OutputYAML OutputName = "yaml"
+	// OutputTemplate renders versions through text/template from --out-template
+	OutputTemplate OutputName = "template"
+	// OutputMatrix writes GitHub Actions strategy matrix {"include":[...]} with an entry per version
+	OutputMatrix OutputName = "matrix"
 )
 
-var knownOutputNames = []OutputName{OutputTEXT, OutputJSON, OutputYAML}
+// DefaultOutName is the name of GitHub Action output with versions
+const DefaultOutName = "versions"
+
+var validOutName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_-]*$`)
+
+var knownOutputNames = []OutputName{OutputTEXT, OutputJSON, OutputYAML, OutputTemplate, OutputMatrix}
+
+// checkOutputNames are formats the result of check mode can be written in
+var checkOutputNames = []OutputName{OutputTEXT, OutputJSON, OutputYAML}
 
 type OutputType interface {
 	Write(version.Versions) error
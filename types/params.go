@@ -3,54 +3,297 @@ package types
 import (
 	"flag"
 	"fmt"
+	"io"
+	"net/http"
 	"os"
 	"slices"
+	"strconv"
 	"strings"
+	"time"
+)
+
+const (
+	// DefaultDockerHubURL is the API base URL of Docker Hub.
+	DefaultDockerHubURL = "https://hub.docker.com"
+	// DefaultMavenURL is the search API base URL of Maven Central.
+	DefaultMavenURL = "https://search.maven.org"
 )
 
 type Params struct {
+	// Command is given by the first argument, Args are positional arguments that follow flags
+	Command           CommandName
+	Args              []string
+	Config            string
+	Listen            string
 	SourceName        SourceName
 	Repo              string
 	FiltersDefinition string
 	Prefix            string
 	MavenGroup        string
 	MavenArtifactID   string
+	File              string
 	OutFormat         OutputName
 	OutNoPrefix       bool
 	OutReverseOrder   bool
 	OutAsAction       bool
-	SSLVerify         bool
+	OutName           string
+	FailOnEmpty       bool
+	ExpectCount       int
+	MinCount          int
+	MaxCount          int
+	OutTemplate       string
+	OutMatrixKeys     string
+	OutMatrixExtra    []string
+	Current           string
+	CurrentFile       string
+	CurrentPattern    string
+	CurrentName       string
+	CurrentPath       string
+	ExitCode          bool
+	Update            bool
+	DryRun            bool
+	Bump              BumpName
+	Branch            string
+	Insecure          bool
+	CABundle          string
+	ClientCert        string
+	ClientKey         string
+	NetworkConfig     string
+	Cache             bool
+	CacheDir          string
+	CacheTTL          time.Duration
+	Offline           bool
 	ShowVersion       bool
 	RetryMax          int
 	RetryInitialDelay int
 	RetryMaxDelay     int
+	Timeout           time.Duration
+	RequestTimeout    time.Duration
+	Concurrency       int
+	EarlyStop         bool
+	HTTPRecord        string
+	HTTPReplay        string
+	DockerHubURL      string
+	MavenURL          string
 	GitHubToken       string
+	GitHubAPI         GitHubAPIName
+	GitHubAPIURL      string
+
+	GitHubAppID             string
+	GitHubAppPrivateKey     string
+	GitHubAppPrivateKeyFile string
+	GitHubAppInstallationID int64
+
+	// NewestVersions is how many newest versions the filter needs, it is set from the filter
+	// when --early-stop is on, 0 means that all versions are needed
+	NewestVersions int
+	// HTTPClient is used by sources instead of a client built from params, serve shares it between requests
+	HTTPClient *http.Client
+	// Credentials keep credentials sources create, serve shares them between requests
+	Credentials *Credentials
+	// RateLimits keep until when hosts are rate limited, serve shares them between requests
+	RateLimits *RateLimits
+	// Log is where warnings and workflow commands of sources are written, stderr of the CLI, nothing when nil
+	Log io.Writer
+
+	// Combine and CombineOf are set for sets of --config file, versions of a set are combined
+	// from versions of the named queries instead of getting them from a source
+	Combine   SetOperation
+	CombineOf []string
 }
 
 func (p *Params) Parse(knownSources Sources) error {
-	flag.StringVar((*string)(&p.SourceName), "source", "",
+	p.register(flag.CommandLine, knownSources)
+	flag.Usage = usage
+	var args []string
+	p.Log = os.Stderr
+	p.Command, args = SplitCommand(os.Args[1:])
+	if !knownCommand(p.Command) {
+		return fmt.Errorf("unknown command %q", p.Command)
+	}
+	if err := flag.CommandLine.Parse(args); err != nil {
+		return err
+	}
+	p.Args = flag.Args()
+	if err := p.validateArgs(); err != nil {
+		return err
+	}
+	// Queries of --config file are parsed and validated by LoadConfig
+	if p.Config != "" || p.ShowVersion {
+		return nil
+	}
+	switch {
+	case p.Command == CommandServe, p.Command == CommandExplain && p.SourceName != "":
+		// Serve takes sources from requests, but needs defaults of the other params
+	case !p.Command.NeedsSource():
+		return nil
+	}
+	// Flags of every source are settings of serve, like --github-token and --mvn-url
+	if p.Command != CommandServe {
+		if err := ValidateSourceFlags(p.SourceName, isSet(flag.CommandLine)); err != nil {
+			return err
+		}
+	}
+	return p.complete(knownSources)
+}
+
+// isSet tells whether a flag is set to a value other than empty or default one, see overrides
+func isSet(fs *flag.FlagSet) func(name string) bool {
+	set := map[string]bool{}
+	fs.Visit(func(f *flag.Flag) {
+		if value := f.Value.String(); value != "" && value != f.DefValue {
+			set[f.Name] = true
+		}
+	})
+	return func(name string) bool {
+		return set[name]
+	}
+}
+
+func usage() {
+	out := flag.CommandLine.Output()
+	_, _ = fmt.Fprintf(out, "Usage: %s [command] [flags]\n\nCommands:\n", os.Args[0])
+	for _, command := range Commands {
+		_, _ = fmt.Fprintf(out, "  %-16s %s\n", command.Name, command.Usage)
+	}
+	_, _ = fmt.Fprintln(out, "\nFlags:")
+	flag.PrintDefaults()
+}
+
+// DefaultParams returns params with default values of the flags, for callers that do not parse flags.
+func DefaultParams() Params {
+	var p Params
+	fs := flag.NewFlagSet("defaults", flag.ContinueOnError)
+	p.register(fs, nil)
+	return p
+}
+
+// LogOutput returns Log, or a writer that discards everything when it is not set
+func (p Params) LogOutput() io.Writer {
+	if p.Log == nil {
+		return io.Discard
+	}
+	return p.Log
+}
+
+// Complete fills params that have defaults from env variables and validates them, like Parse does for flags.
+func (p *Params) Complete(knownSources Sources) error {
+	return p.complete(knownSources)
+}
+
+// register defines flags that set params on fs
+func (p *Params) register(fs *flag.FlagSet, knownSources Sources) {
+	fs.StringVar(&p.Config, "config", "",
+		"YAML file with named queries, which are run concurrently, flags override settings of every query")
+	fs.StringVar(&p.Listen, "listen", ":8080", "Address serve command listens on")
+	fs.StringVar((*string)(&p.SourceName), "source", "",
 		"Version source, one of: "+strings.Join(knownSources.Names(), ", "))
-	flag.StringVar(&p.Repo, "repo", "", "Repository name. "+
+	fs.StringVar(&p.Repo, "repo", "", "Repository name. "+
 		"Examples for dockerhub: ubuntu or alpine/git; for github: golang/go or scylladb/scylla")
-	flag.StringVar(&p.FiltersDefinition, "filters", "",
+	fs.StringVar(&p.FiltersDefinition, "filters", "",
 		"Filters to apply to versions. Example: \"LAST.*.*\" ")
-	flag.StringVar(&p.Prefix, "prefix", "", "Version prefix")
-	flag.StringVar((*string)(&p.OutFormat), "out-format", "text", "Output type: json, yaml, text")
-	flag.BoolVar(&p.OutReverseOrder, "out-reverse-order", false, "Reverse order")
-	flag.BoolVar(&p.OutNoPrefix, "out-no-prefix", false, "Remove prefix from output")
-	flag.StringVar(&p.MavenGroup, "mvn-group", "", "Artifact group to search on the maven")
-	flag.StringVar(&p.MavenArtifactID, "mvn-artifact-id", "", "Artifact ID to search on the maven")
-	flag.BoolVar(&p.OutAsAction, "out-as-action", false, "Output to a GitHub action output")
-	flag.BoolVar(&p.SSLVerify, "ssl-verify", false, "Verify server SSL certificate")
-	flag.BoolVar(&p.ShowVersion, "version", false, "Print version and exit")
-	flag.IntVar(&p.RetryMax, "retry-max", 5, "Maximum number of retries for rate-limited requests")
-	flag.IntVar(&p.RetryInitialDelay, "retry-initial-delay", 1000, "Initial retry delay in milliseconds for exponential backoff")
-	flag.IntVar(&p.RetryMaxDelay, "retry-max-delay", 30000, "Maximum retry delay in milliseconds for exponential backoff")
-	flag.StringVar(&p.GitHubToken, "github-token", "",
+	fs.StringVar(&p.Prefix, "prefix", "", "Version prefix")
+	fs.StringVar((*string)(&p.OutFormat), "out-format", "text", "Output type: json, yaml, text, template, matrix")
+	fs.StringVar(&p.OutTemplate, "out-template", "",
+		"Go template to render versions with --out-format=template, inline or @file to read it from a file")
+	fs.StringVar(&p.OutMatrixKeys, "out-matrix-keys", "version,major,minor,patch",
+		"Fields of matrix entries with --out-format=matrix, field or field=key, fields: "+
+			"version, major, minor, patch, patch-number, extra, prefix, metadata.<name>")
+	fs.Func("out-matrix-extra", "Static key=value fields added to every matrix entry, comma separated, can be repeated",
+		func(value string) error {
+			p.OutMatrixExtra = append(p.OutMatrixExtra, value)
+			return nil
+		})
+	fs.BoolVar(&p.OutReverseOrder, "out-reverse-order", false, "Reverse order")
+	fs.BoolVar(&p.OutNoPrefix, "out-no-prefix", false, "Remove prefix from output")
+	fs.BoolVar(&p.FailOnEmpty, "fail-on-empty", false, "Fail with exit code 3 when no versions match filters")
+	p.ExpectCount, p.MaxCount = -1, -1
+	fs.Func("expect-count", "Fail with exit code 3 unless exactly this number of versions match filters",
+		optionalCount(&p.ExpectCount))
+	fs.IntVar(&p.MinCount, "min-count", 0, "Fail with exit code 3 when fewer versions match filters")
+	fs.Func("max-count", "Fail with exit code 3 when more versions match filters", optionalCount(&p.MaxCount))
+	fs.StringVar(&p.Current, "current", "",
+		"Version currently in use, turns on check mode that reports whether filters select a newer version")
+	fs.StringVar(&p.CurrentFile, "current-file", "",
+		"File to read the current version from, turns on check mode, "+
+			"Dockerfile, go.mod, pom.xml and package.json are recognized by name")
+	fs.StringVar(&p.CurrentPattern, "current-pattern", "",
+		"Regular expression to find the current version in --current-file, "+
+			"by group named version or by the only group")
+	fs.StringVar(&p.CurrentName, "current-name", "",
+		"Image, module, artifact ID or package whose version is read from --current-file, "+
+			"defaults to --repo or --mvn-artifact-id")
+	fs.StringVar(&p.CurrentPath, "current-path", "",
+		"Dot separated path to the current version in --current-file in YAML or JSON, like jobs.test.env.VERSION, "+
+			"with --current-pattern the pattern finds the version in the value")
+	fs.BoolVar(&p.Update, "update", false,
+		"Write the newest version selected by filters to --current-file in place of the current one")
+	fs.BoolVar(&p.DryRun, "dry-run", false, "Print unified diff of --update instead of writing the file")
+	fs.BoolVar(&p.ExitCode, "exit-code", false,
+		"In check mode exit with 4 for a patch, 5 for a minor and 6 for a major update")
+	fs.StringVar((*string)(&p.Bump), "bump", "",
+		"Print the next release version instead of versions, incrementing part of the newest one: "+
+			"patch, minor, major or rc")
+	fs.StringVar(&p.Branch, "branch", "",
+		"Release branch like branch-6.2, --bump computes the next 6.2.x version")
+	fs.StringVar(&p.MavenGroup, "mvn-group", "", "Artifact group to search on the maven")
+	fs.StringVar(&p.MavenArtifactID, "mvn-artifact-id", "", "Artifact ID to search on the maven")
+	fs.StringVar(&p.MavenURL, "mvn-url", DefaultMavenURL, "Maven Central search API base URL")
+	fs.StringVar(&p.File, "file", "",
+		"File to read versions from with --source file, a version per line or a JSON or YAML list, - reads stdin")
+	fs.StringVar(&p.DockerHubURL, "dockerhub-url", DefaultDockerHubURL, "Docker Hub API base URL")
+	fs.BoolVar(&p.OutAsAction, "out-as-action", false, "Output to a GitHub action output")
+	fs.StringVar(&p.OutName, "out-name", DefaultOutName,
+		"Name of GitHub action output with versions, extra outputs like latest are prefixed with it unless it is "+
+			DefaultOutName)
+	fs.BoolVar(&p.Insecure, "insecure", false, "Do not verify server TLS certificates")
+	fs.Bool("ssl-verify", true, "Deprecated: server TLS certificates are verified unless --insecure is set")
+	fs.StringVar(&p.CABundle, "ca-bundle", "",
+		"PEM file or directory of PEM files with CA certificates to trust in addition to system ones")
+	fs.StringVar(&p.ClientCert, "client-cert", "", "PEM file with TLS client certificate")
+	fs.StringVar(&p.ClientKey, "client-key", "", "PEM file with TLS client certificate private key")
+	fs.StringVar(&p.NetworkConfig, "network-config", "",
+		"YAML file with proxy and per-host overrides: proxy, CA bundle, auth header and mirror")
+	fs.BoolVar(&p.ShowVersion, "version", false, "Print version and exit")
+	fs.IntVar(&p.RetryMax, "retry-max", 5, "Maximum number of retries for failed and rate-limited requests")
+	fs.IntVar(&p.RetryInitialDelay, "retry-initial-delay", 1000, "Initial retry delay in milliseconds for exponential backoff")
+	fs.IntVar(&p.RetryMaxDelay, "retry-max-delay", 30000, "Maximum retry delay in milliseconds for exponential backoff")
+	fs.DurationVar(&p.Timeout, "timeout", 0,
+		"Overall timeout for getting versions, e.g. 5m, no limit when 0")
+	fs.DurationVar(&p.RequestTimeout, "request-timeout", time.Minute,
+		"Timeout for a single http request attempt, requests that time out are retried, no limit when 0")
+	fs.IntVar(&p.Concurrency, "concurrency", 4,
+		"Maximum number of pages of paginated API responses fetched at once, 1 fetches pages one by one")
+	fs.BoolVar(&p.EarlyStop, "early-stop", false,
+		"Stop listing versions once the filter is satisfied, e.g. after the newest version for LAST, "+
+			"for sources that list newest versions first: dockerhub-imagetag and github-release")
+	fs.StringVar(&p.HTTPRecord, "http-record", "",
+		"Directory to record http responses to as fixtures, for tests")
+	fs.StringVar(&p.HTTPReplay, "http-replay", "",
+		"Directory to replay http responses from, recorded by --http-record, network is not accessed")
+	fs.BoolVar(&p.Cache, "cache", false,
+		"Cache responses on disk, in $XDG_CACHE_HOME/get-version unless --cache-dir is set")
+	fs.StringVar(&p.CacheDir, "cache-dir", "", "Directory to cache responses in, enables --cache")
+	fs.DurationVar(&p.CacheTTL, "cache-ttl", 10*time.Minute,
+		"How long cached responses are used without revalidation, after that they are revalidated with ETag")
+	fs.BoolVar(&p.Offline, "offline", false, "Serve responses only from cache, never access network, enables --cache")
+	fs.StringVar(&p.GitHubToken, "github-token", "",
 		"GitHub API token (overrides GH_TOKEN/GITHUB_TOKEN env vars)")
+	fs.StringVar((*string)(&p.GitHubAPI), "github-api", string(GitHubAPIAuto),
+		"GitHub API to use: auto, rest, graphql. auto uses graphql when GitHub token is provided")
+	fs.StringVar(&p.GitHubAPIURL, "github-api-url", "",
+		"GitHub API base URL, for GitHub Enterprise Server it is https://<host>/api/v3 (default GITHUB_API_URL env var or "+
+			DefaultGitHubAPIURL+")")
+	fs.StringVar(&p.GitHubAppID, "github-app-id", "",
+		"GitHub App ID to authenticate as an app installation (default GITHUB_APP_ID env var)")
+	fs.StringVar(&p.GitHubAppPrivateKeyFile, "github-app-private-key-file", "",
+		"Path to GitHub App private key, the key can also be provided in GITHUB_APP_PRIVATE_KEY env var")
+	fs.Int64Var(&p.GitHubAppInstallationID, "github-app-installation-id", 0,
+		"GitHub App installation ID, if not set it is looked up by --repo (default GITHUB_APP_INSTALLATION_ID env var)")
+}
 
-	flag.Parse()
-
+// complete fills params that have defaults from env variables and validates them
+func (p *Params) complete(knownSources Sources) error {
 	if p.GitHubToken == "" {
 		if token := os.Getenv("GH_TOKEN"); token != "" {
 			p.GitHubToken = token
@@ -59,17 +302,141 @@ func (p *Params) Parse(knownSources Sources) error {
 		}
 	}
 
+	if p.CacheDir != "" || p.Offline {
+		p.Cache = true
+	}
+
+	if err := p.loadGitHubAppFromEnv(); err != nil {
+		return err
+	}
+
+	if p.GitHubAPIURL == "" {
+		if apiURL := os.Getenv("GITHUB_API_URL"); apiURL != "" {
+			p.GitHubAPIURL = apiURL
+		} else {
+			p.GitHubAPIURL = DefaultGitHubAPIURL
+		}
+	}
+
 	if p.ShowVersion {
 		return nil
 	}
-	if p.SourceName == "" {
+	p.applyCommand()
+	if err := p.validateCommand(); err != nil {
+		return err
+	}
+	if p.SourceName == "" && p.Combine == "" && p.Command != CommandServe {
 		return fmt.Errorf("--source is empty")
 	}
-	if !knownSources.SourceExists(p.SourceName) {
+	if (p.ClientCert == "") != (p.ClientKey == "") {
+		return fmt.Errorf("--client-cert and --client-key should be provided together")
+	}
+	if p.HTTPRecord != "" && p.HTTPReplay != "" {
+		return fmt.Errorf("--http-record and --http-replay can't be used together")
+	}
+	if p.Concurrency < 1 {
+		return fmt.Errorf("--concurrency should be at least 1")
+	}
+	if p.SourceName != "" && !knownSources.SourceExists(p.SourceName) {
 		return fmt.Errorf("unknown source %q", p.SourceName)
 	}
+	if err := p.validateRequiredFlags(); err != nil {
+		return err
+	}
 	if !slices.Contains(knownOutputNames, p.OutFormat) {
 		return fmt.Errorf("unknown output format %q", p.OutFormat)
 	}
+	if !validOutName.MatchString(p.OutName) {
+		return fmt.Errorf("invalid output name %q, it can have only letters, digits, - and _", p.OutName)
+	}
+	if p.FailOnEmpty {
+		p.MinCount = max(p.MinCount, 1)
+	}
+	if p.MaxCount >= 0 && p.MinCount > p.MaxCount {
+		return fmt.Errorf("--min-count %d is greater than --max-count %d", p.MinCount, p.MaxCount)
+	}
+	if p.ExpectCount >= 0 && (p.ExpectCount < p.MinCount || (p.MaxCount >= 0 && p.ExpectCount > p.MaxCount)) {
+		return fmt.Errorf("--expect-count %d contradicts --min-count, --max-count or --fail-on-empty", p.ExpectCount)
+	}
+	if (p.OutFormat == OutputTemplate) != (p.OutTemplate != "") {
+		return fmt.Errorf("--out-format=template and --out-template should be provided together")
+	}
+	if err := p.validateCheckMode(); err != nil {
+		return err
+	}
+	if err := p.validateNextMode(); err != nil {
+		return err
+	}
+	if !slices.Contains(knownGitHubAPINames, p.GitHubAPI) {
+		return fmt.Errorf("unknown github api %q", p.GitHubAPI)
+	}
+	if p.GitHubAPI == GitHubAPIGraphQL && !p.HasGitHubCredentials() {
+		return fmt.Errorf("--github-api=graphql requires GitHub token or GitHub App credentials")
+	}
+	if p.UseGitHubApp() && p.GitHubAppPrivateKey == "" && p.GitHubAppPrivateKeyFile == "" {
+		return fmt.Errorf("--github-app-id requires GitHub App private key")
+	}
+	return nil
+}
+
+// CheckMode tells whether the current version is compared against the source instead of listing versions.
+func (p Params) CheckMode() bool {
+	return p.Current != "" || p.CurrentFile != ""
+}
+
+func (p Params) validateCheckMode() error {
+	if p.Current != "" && p.CurrentFile != "" {
+		return fmt.Errorf("--current and --current-file can't be used together")
+	}
+	if (p.CurrentPattern != "" || p.CurrentName != "" || p.CurrentPath != "") && p.CurrentFile == "" {
+		return fmt.Errorf("--current-pattern, --current-name and --current-path require --current-file")
+	}
+	if p.CurrentName != "" && (p.CurrentPattern != "" || p.CurrentPath != "") {
+		return fmt.Errorf("--current-name can't be used with --current-pattern or --current-path")
+	}
+	if p.Update && p.CurrentFile == "" {
+		return fmt.Errorf("--update requires --current-file")
+	}
+	if p.DryRun && !p.Update {
+		return fmt.Errorf("--dry-run requires --update")
+	}
+	if p.ExitCode && !p.CheckMode() {
+		return fmt.Errorf("--exit-code requires --current or --current-file")
+	}
+	if p.CheckMode() && !slices.Contains(checkOutputNames, p.OutFormat) {
+		return fmt.Errorf("output format %q is not supported with --current and --current-file", p.OutFormat)
+	}
+	return nil
+}
+
+// optionalCount parses a count flag that is not set when it is empty, so that it can be always passed by the action
+func optionalCount(dst *int) func(string) error {
+	return func(value string) error {
+		if value == "" {
+			*dst = -1
+			return nil
+		}
+		count, err := strconv.Atoi(value)
+		if err != nil || count < 0 {
+			return fmt.Errorf("should be a non-negative number")
+		}
+		*dst = count
+		return nil
+	}
+}
+
+// CheckCount checks number of selected versions against --expect-count, --min-count, --max-count
+// and --fail-on-empty.
+func (p Params) CheckCount(count int) error {
+	switch {
+	case p.ExpectCount >= 0 && count != p.ExpectCount:
+		return fmt.Errorf("expected %d versions to match filters, got %d", p.ExpectCount, count)
+	case count == 0 && p.MinCount > 0:
+		return fmt.Errorf("no versions match filters")
+	case count < p.MinCount:
+		return fmt.Errorf("expected at least %d versions to match filters, got %d", p.MinCount, count)
+	case p.MaxCount >= 0 && count > p.MaxCount:
+		return fmt.Errorf("expected at most %d versions to match filters, got %d", p.MaxCount, count)
+	}
 	return nil
 }
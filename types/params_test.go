@@ -0,0 +1,69 @@
+package types
+
+import "testing"
+
+func TestCheckCount(t *testing.T) {
+	tcases := []struct {
+		name      string
+		params    Params
+		count     int
+		expectErr bool
+	}{
+		{name: "no policy, empty", params: Params{ExpectCount: -1, MaxCount: -1}, count: 0},
+		{name: "min count, empty", params: Params{ExpectCount: -1, MinCount: 1, MaxCount: -1}, count: 0, expectErr: true},
+		{name: "min count", params: Params{ExpectCount: -1, MinCount: 2, MaxCount: -1}, count: 2},
+		{name: "below min count", params: Params{ExpectCount: -1, MinCount: 2, MaxCount: -1}, count: 1, expectErr: true},
+		{name: "max count", params: Params{ExpectCount: -1, MaxCount: 1}, count: 1},
+		{name: "above max count", params: Params{ExpectCount: -1, MaxCount: 1}, count: 2, expectErr: true},
+		{name: "expect zero", params: Params{ExpectCount: 0, MaxCount: -1}, count: 0},
+		{name: "expect count", params: Params{ExpectCount: 1, MaxCount: -1}, count: 1},
+		{name: "unexpected count", params: Params{ExpectCount: 1, MaxCount: -1}, count: 2, expectErr: true},
+	}
+
+	for _, tcase := range tcases {
+		t.Run(tcase.name, func(t *testing.T) {
+			err := tcase.params.CheckCount(tcase.count)
+			if tcase.expectErr != (err != nil) {
+				t.Fatalf("expected error %v, got %v", tcase.expectErr, err)
+			}
+		})
+	}
+}
+
+func TestOptionalCount(t *testing.T) {
+	count := 5
+	parse := optionalCount(&count)
+	if err := parse(""); err != nil || count != -1 {
+		t.Fatalf("expected empty value to unset count, got %d, %v", count, err)
+	}
+	if err := parse("3"); err != nil || count != 3 {
+		t.Fatalf("expected count 3, got %d, %v", count, err)
+	}
+	if err := parse("-1"); err == nil {
+		t.Fatalf("expected error for negative count")
+	}
+}
+
+func TestValidateNextMode(t *testing.T) {
+	tcases := []struct {
+		name   string
+		params Params
+		valid  bool
+	}{
+		{name: "bump", params: Params{Bump: BumpRC, OutFormat: OutputTEXT}, valid: true},
+		{name: "branch patch", params: Params{Bump: BumpPatch, Branch: "branch-6.2", OutFormat: OutputJSON}, valid: true},
+		{name: "unknown bump", params: Params{Bump: "build", OutFormat: OutputTEXT}},
+		{name: "branch minor", params: Params{Bump: BumpMinor, Branch: "branch-6.2", OutFormat: OutputTEXT}},
+		{name: "branch without bump", params: Params{Branch: "branch-6.2", OutFormat: OutputTEXT}},
+		{name: "with current", params: Params{Bump: BumpPatch, Current: "6.2.0", OutFormat: OutputTEXT}},
+		{name: "matrix", params: Params{Bump: BumpPatch, OutFormat: OutputMatrix}},
+	}
+
+	for _, tcase := range tcases {
+		t.Run(tcase.name, func(t *testing.T) {
+			if err := tcase.params.validateNextMode(); (err == nil) != tcase.valid {
+				t.Errorf("expected valid %v, got error %v", tcase.valid, err)
+			}
+		})
+	}
+}
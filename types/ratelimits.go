@@ -0,0 +1,38 @@
+package types
+
+import (
+	"sync"
+	"time"
+)
+
+// RateLimits keeps until when hosts are rate limited, so that queries of serve fail right away
+// instead of each of them retrying against a rate limit that is already exceeded.
+type RateLimits struct {
+	mu    sync.Mutex
+	until map[string]time.Time
+}
+
+func NewRateLimits() *RateLimits {
+	return &RateLimits{until: map[string]time.Time{}}
+}
+
+// Limit records that host is rate limited until the time, the later time is kept
+func (l *RateLimits) Limit(host string, until time.Time) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if until.After(l.until[host]) {
+		l.until[host] = until
+	}
+}
+
+// Until returns until when host is rate limited, false when it is not limited at now
+func (l *RateLimits) Until(host string, now time.Time) (time.Time, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	until, ok := l.until[host]
+	if !ok || !now.Before(until) {
+		delete(l.until, host)
+		return time.Time{}, false
+	}
+	return until, true
+}
@@ -0,0 +1,31 @@
+package types
+
+import (
+	"github.com/scylladb-actions/get-version/version"
+)
+
+// SetOperation combines versions of several queries of a config file.
+type SetOperation string
+
+const (
+	// SetUnion gives versions that are in any of the queries
+	SetUnion SetOperation = "union"
+	// SetIntersection gives versions that are in every one of the queries
+	SetIntersection SetOperation = "intersection"
+	// SetDifference gives versions of the first query that are in none of the others
+	SetDifference SetOperation = "difference"
+)
+
+var knownSetOperations = []SetOperation{SetUnion, SetIntersection, SetDifference}
+
+// Apply combines versions of the queries, in the order the queries are listed in the set.
+func (o SetOperation) Apply(first version.Versions, others []version.Versions) version.Versions {
+	switch o {
+	case SetIntersection:
+		return first.Intersect(others...)
+	case SetDifference:
+		return first.Difference(others...)
+	default:
+		return first.Union(others...)
+	}
+}
@@ -1,7 +1,10 @@
 package types
 
 import (
+	"context"
 	"fmt"
+	"slices"
+	"strings"
 
 	"github.com/scylladb-actions/get-version/version"
 )
@@ -11,6 +14,8 @@ const (
 	GitHubRelease     = SourceName("github-release")
 	GitHubTag         = SourceName("github-tag")
 	DockerHubImageTag = SourceName("dockerhub-imagetag")
+	File              = SourceName("file")
+	Stdin             = SourceName("stdin")
 )
 
 type SourceName string
@@ -20,8 +25,26 @@ type IgnoredVersion struct {
 	Reason  error
 }
 
+// NewVersion parses version name that is expected to start with prefix,
+// if it can't be done it returns a reason to ignore the version.
+func NewVersion(name, prefix string) (version.Version, *IgnoredVersion) {
+	if prefix != "" && !strings.HasPrefix(name, prefix) {
+		return version.Version{}, &IgnoredVersion{
+			Version: name,
+			Reason:  fmt.Errorf("version %q does not have prefix %q", name, prefix),
+		}
+	}
+	name = strings.TrimPrefix(name, prefix)
+	ver, err := version.New(name)
+	if err != nil {
+		return version.Version{}, &IgnoredVersion{Version: name, Reason: err}
+	}
+	ver.SetPrefix(prefix)
+	return ver, nil
+}
+
 type Source interface {
-	GetAllVersions() (out version.Versions, ignored []IgnoredVersion, err error)
+	GetAllVersions(ctx context.Context) (out version.Versions, ignored []IgnoredVersion, err error)
 }
 
 type SourceBuilder func(Params) (Source, error)
@@ -52,3 +75,65 @@ func (s Sources) GetSource(p Params) (Source, error) {
 	}
 	return source, nil
 }
+
+var gitHubSources = []SourceName{GitHubRelease, GitHubTag}
+
+// SourceFlag is a flag that applies only to some sources, other flags apply to every source.
+type SourceFlag struct {
+	Name     string
+	Sources  []SourceName
+	Required bool
+	value    func(Params) string
+}
+
+// sourceFlags lists flags of sources, GitHub credentials from env variables are not checked,
+// since they are commonly set for every step of a workflow
+var sourceFlags = []SourceFlag{
+	{Name: "repo", Sources: append([]SourceName{DockerHubImageTag}, gitHubSources...), Required: true,
+		value: func(p Params) string { return p.Repo }},
+	{Name: "mvn-group", Sources: []SourceName{MavenArtifact}, Required: true,
+		value: func(p Params) string { return p.MavenGroup }},
+	{Name: "mvn-artifact-id", Sources: []SourceName{MavenArtifact}, Required: true,
+		value: func(p Params) string { return p.MavenArtifactID }},
+	{Name: "mvn-url", Sources: []SourceName{MavenArtifact}},
+	{Name: "dockerhub-url", Sources: []SourceName{DockerHubImageTag}},
+	{Name: "file", Sources: []SourceName{File}, Required: true,
+		value: func(p Params) string { return p.File }},
+	{Name: "github-token", Sources: gitHubSources},
+	{Name: "github-api", Sources: gitHubSources},
+	{Name: "github-api-url", Sources: gitHubSources},
+	{Name: "github-app-id", Sources: gitHubSources},
+	{Name: "github-app-private-key-file", Sources: gitHubSources},
+	{Name: "github-app-installation-id", Sources: gitHubSources},
+}
+
+// SourceFlags returns flags that apply only to the source.
+func SourceFlags(sourceName SourceName) []SourceFlag {
+	var out []SourceFlag
+	for _, f := range sourceFlags {
+		if slices.Contains(f.Sources, sourceName) {
+			out = append(out, f)
+		}
+	}
+	return out
+}
+
+// ValidateSourceFlags fails for flags that are set and do not apply to the source.
+func ValidateSourceFlags(sourceName SourceName, isSet func(name string) bool) error {
+	for _, f := range sourceFlags {
+		if isSet(f.Name) && !slices.Contains(f.Sources, sourceName) {
+			return fmt.Errorf("--%s does not apply to source %s", f.Name, sourceName)
+		}
+	}
+	return nil
+}
+
+// validateRequiredFlags fails for required flags of the source that are empty
+func (p Params) validateRequiredFlags() error {
+	for _, f := range SourceFlags(p.SourceName) {
+		if f.Required && f.value(p) == "" {
+			return fmt.Errorf("--%s is required for source %s", f.Name, p.SourceName)
+		}
+	}
+	return nil
+}
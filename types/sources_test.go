@@ -0,0 +1,38 @@
+package types
+
+import (
+	"testing"
+)
+
+func TestNewVersion(t *testing.T) {
+	tcases := []struct {
+		name     string
+		prefix   string
+		expected string
+		ignored  string
+	}{
+		{name: "scylla-6.2.1", prefix: "scylla-", expected: "scylla-6.2.1"},
+		{name: "6.2.1", expected: "6.2.1"},
+		{name: "6.2.1", prefix: "scylla-", ignored: `version "6.2.1" does not have prefix "scylla-"`},
+		{name: "scylla-latest", prefix: "scylla-", ignored: "can't convert major \"latest\" to int"},
+	}
+
+	for _, tcase := range tcases {
+		t.Run(tcase.name, func(t *testing.T) {
+			ver, ignored := NewVersion(tcase.name, tcase.prefix)
+			if tcase.ignored != "" {
+				if ignored == nil || ignored.Reason.Error() != tcase.ignored {
+					t.Fatalf("expected version to be ignored with %q, got %v", tcase.ignored, ignored)
+				}
+				return
+			}
+			if ignored != nil {
+				t.Fatalf("unexpected ignored version: %v", ignored.Reason)
+			}
+			if ver.String() != tcase.expected || ver.Prefix() != tcase.prefix {
+				t.Errorf("expected %q with prefix %q, got %q with prefix %q",
+					tcase.expected, tcase.prefix, ver.String(), ver.Prefix())
+			}
+		})
+	}
+}
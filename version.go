@@ -1,3 +1,3 @@
 package main
 
-var buildVersion = "0.4.5"
+var buildVersion = "0.5.0"
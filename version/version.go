@@ -11,6 +11,8 @@ import (
 
 var patchReg = regexp.MustCompile("^([0-9]+)([a-z0-9._-]*)")
 
+var rcNumberReg = regexp.MustCompile(`rc(\d*)`)
+
 func NewPatch(value string) (Patch, error) {
 	patchMatch := patchReg.FindStringSubmatch(value)
 	if len(patchMatch) != 3 {
@@ -104,6 +106,10 @@ func (p Patch) Equal(o Patch) bool {
 	return p == o
 }
 
+// Metadata holds source specific attributes of a version, like the commit a tag points to.
+// It is not taken into account when versions are compared.
+type Metadata map[string]string
+
 type Version struct {
 	major    int
 	majorStr string
@@ -111,12 +117,32 @@ type Version struct {
 	minorStr string
 	patch    Patch
 	prefix   string
+	metadata *Metadata
 }
 
 func (v *Version) SetPrefix(prefix string) {
 	v.prefix = prefix
 }
 
+func (v Version) Prefix() string {
+	return v.prefix
+}
+
+func (v *Version) SetMetadata(metadata Metadata) {
+	if len(metadata) == 0 {
+		v.metadata = nil
+		return
+	}
+	v.metadata = &metadata
+}
+
+func (v Version) Metadata() Metadata {
+	if v.metadata == nil {
+		return nil
+	}
+	return *v.metadata
+}
+
 func (v Version) Major() int {
 	return v.major
 }
@@ -215,6 +241,25 @@ func (v Version) Cmp(o Version) int {
 	return 0
 }
 
+// RCNumber returns release candidate number from extra like -rc2, 0 for rc without number and other versions
+func (v Version) RCNumber() int {
+	match := rcNumberReg.FindStringSubmatch(v.Extra())
+	if match == nil {
+		return 0
+	}
+	number, _ := strconv.Atoi(match[1])
+	return number
+}
+
+// CmpRC is Cmp that also compares release candidates of the same version by their number,
+// so that 6.2.0-rc2 is higher than 6.2.0-rc1.
+func (v Version) CmpRC(o Version) int {
+	if c := v.Cmp(o); c != 0 {
+		return c
+	}
+	return sign(v.RCNumber() - o.RCNumber())
+}
+
 var emptyPatch = Patch{
 	patch: math.MinInt,
 }
@@ -361,6 +406,61 @@ func (v Versions) Order(reverse bool) Versions {
 	return v
 }
 
+// Newest returns the highest version by CmpRC, the first one of equal versions, false when there are no versions.
+func (v Versions) Newest() (Version, bool) {
+	if len(v) == 0 {
+		return Version{}, false
+	}
+	return slices.MaxFunc(v, Version.CmpRC), true
+}
+
+// Union returns versions that are in v or in any of others. Versions are told apart by NoPrefixString,
+// so that versions of sources with different prefixes match, the first occurrence of a version is kept.
+func (v Versions) Union(others ...Versions) Versions {
+	var out Versions
+	seen := map[string]bool{}
+	for _, versions := range append([]Versions{v}, others...) {
+		for _, ver := range versions {
+			if key := ver.NoPrefixString(); !seen[key] {
+				seen[key] = true
+				out = append(out, ver)
+			}
+		}
+	}
+	return out
+}
+
+// Intersect returns versions of v that are in every one of others, see Union for how versions are matched.
+func (v Versions) Intersect(others ...Versions) Versions {
+	return v.keep(others, len(others))
+}
+
+// Difference returns versions of v that are in none of others, see Union for how versions are matched.
+func (v Versions) Difference(others ...Versions) Versions {
+	return v.keep(others, 0)
+}
+
+// keep returns versions of v that are in exactly count of others
+func (v Versions) keep(others []Versions, count int) Versions {
+	found := map[string]int{}
+	for _, versions := range others {
+		counted := map[string]bool{}
+		for _, ver := range versions {
+			if key := ver.NoPrefixString(); !counted[key] {
+				counted[key] = true
+				found[key]++
+			}
+		}
+	}
+	out := Versions{}
+	for _, ver := range v.Union() {
+		if found[ver.NoPrefixString()] == count {
+			out = append(out, ver)
+		}
+	}
+	return out
+}
+
 func intoToStr(values []int) []string {
 	out := make([]string, len(values))
 	for i, val := range values {
@@ -222,3 +222,67 @@ func TestVersion(t *testing.T) {
 		}
 	})
 }
+
+func TestVersionsNewest(t *testing.T) {
+	tcases := []struct {
+		versions []string
+		expected string
+	}{
+		{versions: []string{"6.2.0-rc2", "6.1.5", "6.2.0-rc1"}, expected: "6.2.0-rc2"},
+		{versions: []string{"6.2.0-rc1", "6.2.0-rc2", "6.1.5"}, expected: "6.2.0-rc2"},
+		{versions: []string{"6.2.0-rc2", "6.2.0", "6.2.0-rc10"}, expected: "6.2.0"},
+		{versions: []string{"6.2.0-rc", "6.2.0-rc1"}, expected: "6.2.0-rc1"},
+		{versions: []string{"6.1.5"}, expected: "6.1.5"},
+	}
+
+	for _, tcase := range tcases {
+		t.Run(tcase.expected, func(t *testing.T) {
+			var versions version.Versions
+			for _, value := range tcase.versions {
+				versions = append(versions, version.NewMust(value))
+			}
+			newest, ok := versions.Newest()
+			if !ok || newest.String() != tcase.expected {
+				t.Errorf("expected %s for %v, got %s", tcase.expected, tcase.versions, newest)
+			}
+		})
+	}
+	if _, ok := version.Versions(nil).Newest(); ok {
+		t.Errorf("expected no newest version of no versions")
+	}
+}
+
+func TestVersionsSets(t *testing.T) {
+	parse := func(prefix string, values ...string) version.Versions {
+		var out version.Versions
+		for _, value := range values {
+			v := version.NewMust(value)
+			v.SetPrefix(prefix)
+			out = append(out, v)
+		}
+		return out
+	}
+	releases := parse("scylla-", "6.1.0", "6.2.0", "6.2.1", "6.2.1")
+	images := parse("", "6.1.0", "6.2.0", "6.3.0")
+	nightly := parse("", "6.2.0", "6.3.0")
+
+	tcases := []struct {
+		name     string
+		got      version.Versions
+		expected []string
+	}{
+		{name: "union", got: releases.Union(images, nightly),
+			expected: []string{"scylla-6.1.0", "scylla-6.2.0", "scylla-6.2.1", "6.3.0"}},
+		{name: "intersection", got: releases.Intersect(images, nightly), expected: []string{"scylla-6.2.0"}},
+		{name: "difference", got: releases.Difference(images), expected: []string{"scylla-6.2.1"}},
+		{name: "difference of several", got: images.Difference(releases, nightly), expected: []string{}},
+	}
+
+	for _, tcase := range tcases {
+		t.Run(tcase.name, func(t *testing.T) {
+			if got := tcase.got.AsStringSlice(true); !slices.Equal(got, tcase.expected) {
+				t.Errorf("expected %v, got %v", tcase.expected, got)
+			}
+		})
+	}
+}